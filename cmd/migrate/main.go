@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  status  print the current schema version and the state of every migration
+  up      apply every pending migration`
+
+func printStatus(db datastore.DataStore) error {
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	statusList, err := db.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("schema version: %d\n", version)
+	for _, status := range statusList {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%4d  %-8s %s\n", status.Version, state, status.Description)
+	}
+
+	return nil
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+
+	switch os.Args[1] {
+	case "status":
+		cfg.DataStore.ReadOnly = true
+	case "up":
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	db, err := databases.GetDataStore(cfg.DataStore)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDataStoreInUse) {
+			log.Fatalf("%v, stop the service before running migrate", err)
+		}
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if os.Args[1] == "up" {
+		if err := db.Init(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := printStatus(db); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -3,27 +3,71 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/pmoura-dev/esr-service/internal/broker"
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
 	"github.com/pmoura-dev/esr-service/internal/config"
 	"github.com/pmoura-dev/esr-service/internal/datastore/databases"
+	"github.com/pmoura-dev/esr-service/internal/events"
 	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	commands_handlers "github.com/pmoura-dev/esr-service/internal/handlers/http_handlers/commands"
+	dead_letters_handlers "github.com/pmoura-dev/esr-service/internal/handlers/http_handlers/dead_letters"
 	entities_handlers "github.com/pmoura-dev/esr-service/internal/handlers/http_handlers/entities"
+	report_subscriptions_handlers "github.com/pmoura-dev/esr-service/internal/handlers/http_handlers/report_subscriptions"
+	"github.com/pmoura-dev/esr-service/internal/handlers/pubsub_handlers"
+	entities_pubsub_handlers "github.com/pmoura-dev/esr-service/internal/handlers/pubsub_handlers/entities"
 	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/services/command"
+	"github.com/pmoura-dev/esr-service/internal/services/dead_letter"
 	"github.com/pmoura-dev/esr-service/internal/services/entity"
+	"github.com/pmoura-dev/esr-service/internal/services/outbox"
+	"github.com/pmoura-dev/esr-service/internal/services/report_subscription"
+	"github.com/pmoura-dev/esr-service/internal/workers"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	"github.com/ThreeDotsLabs/watermill/message/router/plugin"
 	"github.com/gin-gonic/gin"
 )
 
-func setupHTTPRouter(entityService services.EntityService) *gin.Engine {
+type pubSubHandler struct {
+	topic   string
+	handler message.NoPublishHandlerFunc
+}
+
+// pubSubHandlers are the inbound message handlers, by the name they are
+// registered with. Dead letters are replayed by the handler of the same name.
+var pubSubHandlers = map[string]pubSubHandler{
+	"report_state": {
+		topic:   entities_pubsub_handlers.ReportStateTopic,
+		handler: entities_pubsub_handlers.ReportState,
+	},
+	"ack_command": {
+		topic:   entities_pubsub_handlers.AckCommandTopic,
+		handler: entities_pubsub_handlers.AckCommand,
+	},
+}
+
+func setupHTTPRouter(
+	entityService services.EntityService,
+	commandService services.CommandService,
+	reportSubscriptionService services.ReportSubscriptionService,
+	deadLetterService services.DeadLetterService,
+	bus *events.Bus,
+) *gin.Engine {
 	router := gin.Default()
 
 	v1 := router.Group("/v1")
 	{
 		http_handlers.EntityService = entityService
+		http_handlers.CommandService = commandService
+		http_handlers.ReportSubscriptionService = reportSubscriptionService
+		http_handlers.DeadLetterService = deadLetterService
+		http_handlers.Events = bus
 
 		entityGroup := v1.Group("/entities")
 		{
@@ -32,25 +76,93 @@ func setupHTTPRouter(entityService services.EntityService) *gin.Engine {
 			entityGroup.POST("/", entities_handlers.AddEntity)
 			entityGroup.DELETE("/:entity_id", entities_handlers.DeleteEntity)
 			entityGroup.POST("/:entity_id/commands", entities_handlers.NewCommand)
+			entityGroup.GET("/:entity_id/commands", entities_handlers.ListEntityCommands)
+			entityGroup.GET("/:entity_id/events", entities_handlers.StreamEntityEvents)
+
+			reportSubscriptionGroup := entityGroup.Group("/:entity_id/report-subscriptions")
+			{
+				reportSubscriptionGroup.GET("/:subscription_id", report_subscriptions_handlers.GetReportSubscriptionByID)
+				reportSubscriptionGroup.GET("/", report_subscriptions_handlers.ListReportSubscriptions)
+				reportSubscriptionGroup.POST("/", report_subscriptions_handlers.AddReportSubscription)
+				reportSubscriptionGroup.DELETE("/:subscription_id", report_subscriptions_handlers.DeleteReportSubscription)
+				reportSubscriptionGroup.POST("/:subscription_id/activate", report_subscriptions_handlers.ActivateReportSubscription)
+				reportSubscriptionGroup.POST("/:subscription_id/deactivate", report_subscriptions_handlers.DeactivateReportSubscription)
+			}
+		}
+
+		commandGroup := v1.Group("/commands")
+		{
+			commandGroup.GET("/:command_id", commands_handlers.GetCommandByID)
+			commandGroup.GET("/", commands_handlers.ListCommands)
+			commandGroup.GET("/:command_id/events", commands_handlers.StreamCommandEvents)
+		}
+
+		adminGroup := v1.Group("/admin")
+		{
+			deadLetterGroup := adminGroup.Group("/dead-letters")
+			{
+				deadLetterGroup.GET("/:dead_letter_id", dead_letters_handlers.GetDeadLetterByID)
+				deadLetterGroup.GET("/", dead_letters_handlers.ListDeadLetters)
+				deadLetterGroup.DELETE("/:dead_letter_id", dead_letters_handlers.DeleteDeadLetter)
+				deadLetterGroup.POST("/:dead_letter_id/replay", dead_letters_handlers.ReplayDeadLetter)
+			}
 		}
 	}
 	return router
 }
 
-func setupPubSubRouter() (*message.Router, error) {
-	router, err := message.NewRouter(message.RouterConfig{}, watermill.NewSlogLogger(nil))
+func setupPubSubRouter(
+	bk broker.Broker,
+	cd codec.Codec,
+	retryConfig config.RetryConfig,
+	entityService services.EntityService,
+	deadLetterService services.DeadLetterService,
+) (*message.Router, error) {
+	logger := watermill.NewSlogLogger(nil)
+
+	router, err := message.NewRouter(message.RouterConfig{}, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	router.AddPlugin(plugin.SignalsHandler)
 
+	pubsub_handlers.EntityService = entityService
+	pubsub_handlers.DeadLetterService = deadLetterService
+	pubsub_handlers.Codec = cd
+
+	poisonQueue, err := middleware.PoisonQueue(pubsub_handlers.DeadLetterPublisher{}, pubsub_handlers.DeadLetterTopic)
+	if err != nil {
+		return nil, err
+	}
+
+	// middleware run in the order they are added: a message is dead-lettered
+	// once every retry failed, and panics are retried like any other error
+	router.AddMiddleware(
+		poisonQueue,
+		middleware.Retry{
+			MaxRetries:      retryConfig.MaxRetries,
+			InitialInterval: retryConfig.InitialInterval,
+			MaxInterval:     retryConfig.MaxInterval,
+			Multiplier:      2,
+			Logger:          logger,
+		}.Middleware,
+		middleware.Recoverer,
+	)
+
+	for name, h := range pubSubHandlers {
+		router.AddNoPublisherHandler(name, bk.Format(h.topic), bk.GetSubscriber(), h.handler)
+	}
+
 	return router, nil
 }
 
 func main() {
 
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
 
 	// Initialize datastore
 	db, err := databases.GetDataStore(cfg.DataStore)
@@ -70,22 +182,48 @@ func main() {
 	}
 	defer bk.Close()
 
+	cd, err := codec.GetCodec(cfg.Broker)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bus := events.NewBus()
+
 	// Services
-	entityService := entity.NewBaseEntityService(db, bk)
+	entityService := entity.NewBaseEntityService(db, bk, cd, bus, cfg.Command.DefaultTimeout)
+	commandService := command.NewBaseCommandService(db)
+	reportSubscriptionService := report_subscription.NewBaseReportSubscriptionService(db, bk, cd)
+	outboxService := outbox.NewBaseOutboxService(db, bk, cfg.Outbox.BatchSize, cfg.Outbox.Retention, cfg.Outbox.ClaimTimeout)
+
+	replayHandlers := make(map[string]message.NoPublishHandlerFunc, len(pubSubHandlers))
+	for name, h := range pubSubHandlers {
+		replayHandlers[name] = h.handler
+	}
+	deadLetterService := dead_letter.NewBaseDeadLetterService(db, replayHandlers)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Workers
+	commandReaper := workers.NewCommandReaper(entityService, cfg.Command.ReaperInterval)
+	go commandReaper.Run(ctx)
+
+	outboxRelay := workers.NewOutboxRelay(outboxService, cfg.Outbox.RelayInterval)
+	go outboxRelay.Run(ctx)
 
-	httpRouter := setupHTTPRouter(entityService)
+	httpRouter := setupHTTPRouter(entityService, commandService, reportSubscriptionService, deadLetterService, bus)
 	go func() {
 		if err := httpRouter.Run(); err != nil {
 			log.Fatal(err)
 		}
 	}()
 
-	pubSubRouter, err := setupPubSubRouter()
+	pubSubRouter, err := setupPubSubRouter(bk, cd, cfg.Retry, entityService, deadLetterService)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if err := pubSubRouter.Run(context.Background()); err != nil {
+	if err := pubSubRouter.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
 }
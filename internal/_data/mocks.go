@@ -35,6 +35,16 @@ var (
 		"issued_at": "2011-11-10T23:00:00Z",
 		"resolved_at": "2011-11-10T23:00:10Z"
 	}`
+	MockCommand2PendingTimeout = `{
+		"id": "cmd4",
+		"entity_id": "2",
+		"desired_state": {
+			"power": "on"
+		},
+		"status": "pending",
+		"issued_at": "2012-11-10T23:00:00Z",
+		"timeout_at": "2012-11-10T23:00:30Z"
+	}`
 	MockCommandInvalid = `{
 		"status": "random"
 	}`
@@ -64,4 +74,32 @@ var (
 	MockReportSubscriptionInvalid = `{
 		"report_type": "random"
 	}`
+
+	MockState1Off = `{
+		"id": 1,
+		"entity_id": "1",
+		"state": {
+			"power": "off"
+		},
+		"reported_at": "2009-11-10T23:00:00Z"
+	}`
+	MockState1On = `{
+		"id": 2,
+		"entity_id": "1",
+		"state": {
+			"power": "on"
+		},
+		"reported_at": "2010-11-10T23:00:00Z"
+	}`
+	MockState2On = `{
+		"id": 3,
+		"entity_id": "2",
+		"state": {
+			"power": "on"
+		},
+		"reported_at": "2011-11-10T23:00:00Z"
+	}`
+	MockStateInvalid = `{
+		"reported_at": "random"
+	}`
 )
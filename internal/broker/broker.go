@@ -3,6 +3,9 @@ package broker
 import (
 	"fmt"
 
+	"github.com/pmoura-dev/esr-service/internal/broker/brokers/inmemory"
+	"github.com/pmoura-dev/esr-service/internal/broker/brokers/mqtt"
+	"github.com/pmoura-dev/esr-service/internal/broker/brokers/nats"
 	"github.com/pmoura-dev/esr-service/internal/broker/brokers/rabbitmq"
 	"github.com/pmoura-dev/esr-service/internal/config"
 
@@ -22,6 +25,12 @@ func GetBroker(config config.BrokerConfig) (Broker, error) {
 	switch config.BrokerType {
 	case rabbitmq.Name:
 		return rabbitmq.NewRabbitMQBroker(config)
+	case mqtt.Name:
+		return mqtt.NewMQTTBroker(config)
+	case nats.Name:
+		return nats.NewNATSBroker(config)
+	case inmemory.Name:
+		return inmemory.NewInMemoryBroker(config)
 	default:
 		return nil, fmt.Errorf("unknown broker type: %s", config.BrokerType)
 	}
@@ -0,0 +1,128 @@
+package inmemory
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+)
+
+const (
+	Name = "inmemory"
+
+	wildcard = "*"
+)
+
+// Broker is an in-process broker backed by a watermill GoChannel.
+//
+// GoChannel only delivers messages to exact topic matches, so the broker keeps
+// track of wildcard subscriptions (e.g. 'entities/*/state') and forwards every
+// published message to the patterns its topic matches.
+type Broker struct {
+	pubSub *gochannel.GoChannel
+
+	mu       sync.RWMutex
+	patterns map[string]struct{}
+}
+
+func NewInMemoryBroker(_ config.BrokerConfig) (*Broker, error) {
+	pubSub := gochannel.NewGoChannel(gochannel.Config{}, watermill.NewSlogLogger(nil))
+
+	return &Broker{
+		pubSub:   pubSub,
+		patterns: make(map[string]struct{}),
+	}, nil
+}
+
+func (b *Broker) GetSubscriber() message.Subscriber {
+	return &subscriber{broker: b}
+}
+
+func (b *Broker) GetPublisher() message.Publisher {
+	return &publisher{broker: b}
+}
+
+func (b *Broker) Format(topic string) string {
+	return topic
+}
+
+func (b *Broker) Close() {
+	_ = b.pubSub.Close()
+}
+
+func (b *Broker) matchingPatterns(topic string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var matches []string
+	for pattern := range b.patterns {
+		if pattern == topic {
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, topic); ok {
+			matches = append(matches, pattern)
+		}
+	}
+
+	return matches
+}
+
+type subscriber struct {
+	broker *Broker
+}
+
+func (s *subscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	if strings.Contains(topic, wildcard) {
+		s.broker.mu.Lock()
+		s.broker.patterns[topic] = struct{}{}
+		s.broker.mu.Unlock()
+	}
+
+	return s.broker.pubSub.Subscribe(ctx, topic)
+}
+
+// Close is a no-op, the underlying GoChannel is closed by the broker.
+func (s *subscriber) Close() error {
+	return nil
+}
+
+type publisher struct {
+	broker *Broker
+}
+
+// Publish sets the topic on copies of the messages, as received messages have
+// it, so the messages of the caller are left as they are.
+func (p *publisher) Publish(topic string, messages ...*message.Message) error {
+	received := make([]*message.Message, 0, len(messages))
+	for _, msg := range messages {
+		msg = msg.Copy()
+		msg.Metadata.Set(codec.MetadataReceivedTopic, topic)
+		received = append(received, msg)
+	}
+	messages = received
+
+	if err := p.broker.pubSub.Publish(topic, messages...); err != nil {
+		return err
+	}
+
+	for _, pattern := range p.broker.matchingPatterns(topic) {
+		if err := p.broker.pubSub.Publish(pattern, messages...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close is a no-op, the underlying GoChannel is closed by the broker.
+func (p *publisher) Close() error {
+	return nil
+}
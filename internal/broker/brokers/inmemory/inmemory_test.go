@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	tests := []struct {
+		name string
+
+		subscribeTopic string
+		publishTopic   string
+		wantDelivery   bool
+	}{
+		{
+			name:           "Exact Topic",
+			subscribeTopic: "entities/1/update",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/state",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic - No Match",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bk, err := NewInMemoryBroker(config.BrokerConfig{})
+			if err != nil {
+				t.Fatalf("failed to create broker: %v", err)
+			}
+			t.Cleanup(bk.Close)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			messages, err := bk.GetSubscriber().Subscribe(ctx, bk.Format(tt.subscribeTopic))
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+
+			msg := message.NewMessage("msg1", []byte(`{"power": "on"}`))
+			// a topic set by the publisher must not be trusted
+			msg.Metadata.Set(codec.MetadataReceivedTopic, "entities/2/state")
+
+			if err := bk.GetPublisher().Publish(bk.Format(tt.publishTopic), msg); err != nil {
+				t.Fatalf("failed to publish: %v", err)
+			}
+
+			select {
+			case got := <-messages:
+				got.Ack()
+				if !tt.wantDelivery {
+					t.Errorf("Test failed. Unexpected message: %s", got.UUID)
+					return
+				}
+
+				if got.UUID != msg.UUID || string(got.Payload) != string(msg.Payload) {
+					t.Errorf("Test failed. Expected: %s %s, Got: %s %s", msg.UUID, msg.Payload, got.UUID, got.Payload)
+				}
+
+				if topic := got.Metadata.Get(codec.MetadataReceivedTopic); topic != tt.publishTopic {
+					t.Errorf("Test failed. Expected: %s, Got: %s", tt.publishTopic, topic)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantDelivery {
+					t.Errorf("Test failed. Message was not delivered")
+				}
+			}
+		})
+	}
+}
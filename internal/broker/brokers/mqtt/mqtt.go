@@ -0,0 +1,282 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	Name = "mqtt"
+
+	wildcard = "*"
+
+	singleLevelWildcard = "+"
+
+	operationTimeout  = 10 * time.Second
+	disconnectQuiesce = 250 // milliseconds
+)
+
+var (
+	ErrTimeout           = errors.New("mqtt operation timed out")
+	ErrAlreadySubscribed = errors.New("topic already subscribed")
+)
+
+// Broker connects the service to an MQTT broker, e.g. Mosquitto, or the MQTT
+// plugin of RabbitMQ.
+//
+// MQTT 3.1.1 messages only carry a payload, so message metadata is neither
+// published nor received, except for the topic of received messages. Codecs
+// that keep event attributes in metadata, like CloudEvents binary mode, cannot
+// be used with this broker.
+//
+// Messages are only acknowledged to the MQTT broker once the handler acks them,
+// and every subscription is restored when the client reconnects.
+type Broker struct {
+	client paho.Client
+	qos    byte
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+}
+
+func NewMQTTBroker(config config.BrokerConfig) (*Broker, error) {
+	if config.Codec == codec.NameCloudEventsBinary {
+		return nil, fmt.Errorf("codec %s requires message metadata, which MQTT does not support", config.Codec)
+	}
+
+	if config.QoS < 0 || config.QoS > 2 {
+		return nil, fmt.Errorf("invalid MQTT QoS: %d", config.QoS)
+	}
+
+	tlsConfig, err := config.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "tcp"
+	if tlsConfig != nil {
+		scheme = "ssl"
+	}
+
+	b := &Broker{
+		qos:           byte(config.QoS),
+		subscriptions: make(map[string]*subscription),
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(fmt.Sprintf("%s://%s:%d", scheme, config.Host, config.Port)).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetCleanSession(config.CleanSession).
+		SetTLSConfig(tlsConfig).
+		SetAutoReconnect(true).
+		SetAutoAckDisabled(true).
+		// handlers run concurrently, so a slow one never blocks the connection
+		SetOrderMatters(false).
+		SetOnConnectHandler(b.resubscribe)
+
+	b.client = paho.NewClient(opts)
+
+	if err := wait(b.client.Connect()); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+
+	return b, nil
+}
+
+func (b *Broker) GetSubscriber() message.Subscriber {
+	return &subscriber{broker: b}
+}
+
+func (b *Broker) GetPublisher() message.Publisher {
+	return &publisher{broker: b}
+}
+
+// Format maps the single-level wildcard used by the service ('*') to the MQTT
+// one ('+'). Topics already written in MQTT syntax, including '#', are kept.
+func (b *Broker) Format(topic string) string {
+	levels := strings.Split(topic, "/")
+	for i, level := range levels {
+		if level == wildcard {
+			levels[i] = singleLevelWildcard
+		}
+	}
+
+	return strings.Join(levels, "/")
+}
+
+func (b *Broker) Close() {
+	b.mu.Lock()
+	subscriptions := b.subscriptions
+	b.subscriptions = make(map[string]*subscription)
+	b.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.close()
+	}
+
+	b.client.Disconnect(disconnectQuiesce)
+}
+
+// resubscribe restores the subscriptions after a reconnection, since a clean
+// session starts without any.
+func (b *Broker) resubscribe(client paho.Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for topic, sub := range b.subscriptions {
+		_ = wait(client.Subscribe(topic, b.qos, sub.handle))
+	}
+}
+
+func (b *Broker) unsubscribe(topic string) {
+	b.mu.Lock()
+	sub, ok := b.subscriptions[topic]
+	delete(b.subscriptions, topic)
+	b.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	_ = wait(b.client.Unsubscribe(topic))
+	sub.close()
+}
+
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(operationTimeout) {
+		return ErrTimeout
+	}
+
+	return token.Error()
+}
+
+type subscriber struct {
+	broker *Broker
+}
+
+func (s *subscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	sub := &subscription{
+		ctx:     ctx,
+		output:  make(chan *message.Message),
+		closing: make(chan struct{}),
+	}
+
+	s.broker.mu.Lock()
+	if _, ok := s.broker.subscriptions[topic]; ok {
+		s.broker.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAlreadySubscribed, topic)
+	}
+	s.broker.subscriptions[topic] = sub
+	s.broker.mu.Unlock()
+
+	if err := wait(s.broker.client.Subscribe(topic, s.broker.qos, sub.handle)); err != nil {
+		s.broker.unsubscribe(topic)
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.broker.unsubscribe(topic)
+		case <-sub.closing:
+		}
+	}()
+
+	return sub.output, nil
+}
+
+// Close is a no-op, subscriptions are closed by the broker.
+func (s *subscriber) Close() error {
+	return nil
+}
+
+type subscription struct {
+	ctx    context.Context
+	output chan *message.Message
+
+	mu       sync.Mutex
+	closed   bool
+	closing  chan struct{}
+	handlers sync.WaitGroup
+}
+
+// handle delivers a message to the subscription until it is acked, or the
+// subscription is closed. Nacked messages are delivered again.
+func (s *subscription) handle(_ paho.Client, m paho.Message) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.handlers.Add(1)
+	s.mu.Unlock()
+
+	defer s.handlers.Done()
+
+	for {
+		msg := message.NewMessage(watermill.NewUUID(), m.Payload())
+		msg.Metadata.Set(codec.MetadataReceivedTopic, m.Topic())
+		msg.SetContext(s.ctx)
+
+		select {
+		case s.output <- msg:
+		case <-s.closing:
+			return
+		}
+
+		select {
+		case <-msg.Acked():
+			m.Ack()
+			return
+		case <-msg.Nacked():
+		case <-s.closing:
+			return
+		}
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.closing)
+	s.mu.Unlock()
+
+	s.handlers.Wait()
+	close(s.output)
+}
+
+type publisher struct {
+	broker *Broker
+}
+
+func (p *publisher) Publish(topic string, messages ...*message.Message) error {
+	for _, msg := range messages {
+		if err := wait(p.broker.client.Publish(topic, p.broker.qos, false, []byte(msg.Payload))); err != nil {
+			return fmt.Errorf("failed to publish message %s: %w", msg.UUID, err)
+		}
+	}
+
+	return nil
+}
+
+// Close is a no-op, the client is disconnected by the broker.
+func (p *publisher) Close() error {
+	return nil
+}
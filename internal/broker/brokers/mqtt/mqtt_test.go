@@ -0,0 +1,331 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input    string
+		expected string
+	}{
+		{
+			name:     "Exact Topic",
+			input:    "entities/1/update",
+			expected: "entities/1/update",
+		},
+		{
+			name:     "Single-Level Wildcard",
+			input:    "entities/*/commands/*/ack",
+			expected: "entities/+/commands/+/ack",
+		},
+		{
+			name:     "MQTT Wildcards",
+			input:    "entities/+/#",
+			expected: "entities/+/#",
+		},
+		{
+			name:     "Wildcard In Level",
+			input:    "entities/lamp*/state",
+			expected: "entities/lamp*/state",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Broker{}).Format(tt.input); got != tt.expected {
+				t.Errorf("Test failed. Expected: %s, Got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	tests := []struct {
+		name string
+
+		subscribeTopic string
+		publishTopic   string
+		wantDelivery   bool
+	}{
+		{
+			name:           "Exact Topic",
+			subscribeTopic: "entities/1/update",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/state",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Multi-Level Wildcard Topic",
+			subscribeTopic: "entities/#",
+			publishTopic:   "entities/1/commands/cmd1/ack",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic - No Match",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   false,
+		},
+	}
+
+	brokerConfig := setupBroker(t)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := brokerConfig
+			cfg.ClientID = fmt.Sprintf("esr-service-test-%d", i)
+
+			bk, err := NewMQTTBroker(cfg)
+			if err != nil {
+				t.Fatalf("failed to create broker: %v", err)
+			}
+			t.Cleanup(bk.Close)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			messages, err := bk.GetSubscriber().Subscribe(ctx, bk.Format(tt.subscribeTopic))
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+
+			msg := message.NewMessage("msg1", []byte(`{"power": "on"}`))
+			if err := bk.GetPublisher().Publish(bk.Format(tt.publishTopic), msg); err != nil {
+				t.Fatalf("failed to publish: %v", err)
+			}
+
+			select {
+			case got := <-messages:
+				got.Ack()
+				if !tt.wantDelivery {
+					t.Errorf("Test failed. Unexpected message: %s", got.Payload)
+					return
+				}
+
+				if string(got.Payload) != string(msg.Payload) {
+					t.Errorf("Test failed. Expected: %s, Got: %s", msg.Payload, got.Payload)
+				}
+
+				if topic := got.Metadata.Get(codec.MetadataReceivedTopic); topic != tt.publishTopic {
+					t.Errorf("Test failed. Expected: %s, Got: %s", tt.publishTopic, topic)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantDelivery {
+					t.Errorf("Test failed. Message was not delivered")
+				}
+			}
+		})
+	}
+}
+
+// TestCloudEventsStructured checks that structured mode events are decoded
+// although MQTT drops their content type.
+func TestCloudEventsStructured(t *testing.T) {
+	cfg := setupBroker(t)
+	cfg.ClientID = "esr-service-test-cloudevents"
+	cfg.Codec = codec.NameCloudEventsStructured
+
+	bk, err := NewMQTTBroker(cfg)
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	t.Cleanup(bk.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	messages, err := bk.GetSubscriber().Subscribe(ctx, bk.Format("entities/*/state"))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	c := codec.NewCloudEventsCodec("esr-service", codec.CloudEventsStructured, codec.CloudEventsBindingKafka)
+
+	expected := codec.Event{
+		ID:          "msg1",
+		Type:        "esr.state.reported",
+		Source:      "lamp-1",
+		Subject:     "1",
+		Time:        time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		ContentType: codec.ContentTypeJSON,
+		Data:        []byte(`{"power":"on"}`),
+	}
+
+	msg, err := c.Encode(expected)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+
+	if err := bk.GetPublisher().Publish("entities/1/state", msg); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	select {
+	case received := <-messages:
+		received.Ack()
+
+		got, err := c.Decode(received)
+		if err != nil {
+			t.Errorf("Test failed. Unexpected error: %v", err)
+			return
+		}
+
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("Test failed. Message was not delivered")
+	}
+}
+
+func TestNackRedelivery(t *testing.T) {
+	cfg := setupBroker(t)
+	cfg.ClientID = "esr-service-test-nack"
+
+	bk, err := NewMQTTBroker(cfg)
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	t.Cleanup(bk.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	messages, err := bk.GetSubscriber().Subscribe(ctx, "entities/1/update")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	if err := bk.GetPublisher().Publish("entities/1/update", message.NewMessage("msg1", []byte("on"))); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	for _, ack := range []bool{false, true} {
+		select {
+		case got := <-messages:
+			if string(got.Payload) != "on" {
+				t.Errorf("Test failed. Expected: on, Got: %s", got.Payload)
+			}
+
+			if ack {
+				got.Ack()
+			} else {
+				got.Nack()
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("Test failed. Message was not delivered")
+		}
+	}
+
+	// cancelling the context ends the subscription
+	cancel()
+
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Errorf("Test failed. Expected the subscription to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Test failed. Subscription was not closed")
+	}
+}
+
+func TestNewMQTTBroker(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputConfig config.BrokerConfig
+	}{
+		{
+			name:        "Error - Binary CloudEvents",
+			inputConfig: config.BrokerConfig{Codec: codec.NameCloudEventsBinary},
+		},
+		{
+			name:        "Error - Invalid QoS",
+			inputConfig: config.BrokerConfig{QoS: 3},
+		},
+		{
+			name:        "Error - Missing CA File",
+			inputConfig: config.BrokerConfig{TLS: config.TLSConfig{Enabled: true, CAFile: "missing.pem"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMQTTBroker(tt.inputConfig); err == nil {
+				t.Errorf("Test failed. Expected an error")
+			}
+		})
+	}
+}
+
+// setupBroker returns the configuration of the MQTT broker to test against:
+// the one set with ESR_TEST_MQTT_HOST and ESR_TEST_MQTT_PORT if any, e.g. a
+// local Mosquitto, or otherwise an embedded broker.
+func setupBroker(t *testing.T) config.BrokerConfig {
+	t.Helper()
+
+	cfg := config.BrokerConfig{
+		Codec:        "json",
+		QoS:          1,
+		CleanSession: true,
+	}
+
+	if host := os.Getenv("ESR_TEST_MQTT_HOST"); host != "" {
+		port, err := strconv.Atoi(os.Getenv("ESR_TEST_MQTT_PORT"))
+		if err != nil {
+			port = 1883
+		}
+
+		cfg.Host = host
+		cfg.Port = port
+		return cfg
+	}
+
+	server := mochi.New(nil)
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatalf("failed to configure MQTT server: %v", err)
+	}
+
+	listener := listeners.NewTCP(listeners.Config{ID: "test", Address: "127.0.0.1:0"})
+	if err := server.AddListener(listener); err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	if err := server.Serve(); err != nil {
+		t.Fatalf("failed to start MQTT server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	host, port, err := net.SplitHostPort(listener.Address())
+	if err != nil {
+		t.Fatalf("failed to read MQTT server address: %v", err)
+	}
+
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+	return cfg
+}
@@ -0,0 +1,221 @@
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill"
+	wmnats "github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+const (
+	Name = "nats"
+
+	// streamSubjects are the subjects stored in the stream when the broker
+	// creates it, which covers every topic of the service.
+	streamSubjects = "entities.>"
+
+	ackWaitTimeout = 30 * time.Second
+
+	headerContentType = "Content-Type"
+)
+
+// consumerNameReplacer removes the characters NATS does not allow in consumer
+// names from a subject.
+var consumerNameReplacer = strings.NewReplacer(".", "_", "*", "any", ">", "all")
+
+// Broker connects the service to a NATS server with JetStream enabled.
+//
+// Messages are stored in a single stream, created on startup if missing, and
+// every subscribed topic gets its own durable consumer. The consumer is also
+// a queue group, so instances of the service sharing the consumer prefix
+// split the messages of a topic between them.
+type Broker struct {
+	conn *natsgo.Conn
+
+	subscriber *wmnats.Subscriber
+	publisher  *wmnats.Publisher
+}
+
+func NewNATSBroker(config config.BrokerConfig) (*Broker, error) {
+	if config.Stream == "" || config.Consumer == "" {
+		return nil, errors.New("a JetStream stream and consumer prefix are required")
+	}
+
+	tlsConfig, err := config.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []natsgo.Option{
+		natsgo.Name(config.ClientID),
+		natsgo.MaxReconnects(-1),
+	}
+
+	if config.Username != "" {
+		options = append(options, natsgo.UserInfo(config.Username, config.Password))
+	}
+
+	if tlsConfig != nil {
+		options = append(options, natsgo.Secure(tlsConfig))
+	}
+
+	conn, err := natsgo.Connect(fmt.Sprintf("nats://%s:%d", config.Host, config.Port), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS server: %w", err)
+	}
+
+	if err := ensureStream(conn, config.Stream); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	consumerName := func(prefix string, topic string) string {
+		return prefix + "_" + consumerNameReplacer.Replace(topic)
+	}
+
+	jetStreamConfig := wmnats.JetStreamConfig{
+		SubscribeOptions: []natsgo.SubOpt{
+			natsgo.BindStream(config.Stream),
+			natsgo.AckExplicit(),
+		},
+		// the message UUID deduplicates messages published more than once,
+		// e.g. by the outbox relay
+		TrackMsgId:        true,
+		DurablePrefix:     config.Consumer,
+		DurableCalculator: consumerName,
+	}
+
+	logger := watermill.NewSlogLogger(nil)
+
+	subscriber, err := wmnats.NewSubscriberWithNatsConn(conn, wmnats.SubscriberSubscriptionConfig{
+		AckWaitTimeout: ackWaitTimeout,
+		// the queue group has to match the durable consumer name
+		QueueGroupPrefix: config.Consumer,
+		SubjectCalculator: func(queueGroupPrefix string, topic string) *wmnats.SubjectDetail {
+			return &wmnats.SubjectDetail{
+				Primary:    topic,
+				QueueGroup: consumerName(queueGroupPrefix, topic),
+			}
+		},
+		JetStream:   jetStreamConfig,
+		Unmarshaler: &marshaler{},
+	}, logger)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	publisher, err := wmnats.NewPublisherWithNatsConn(conn, wmnats.PublisherPublishConfig{
+		Marshaler:         &marshaler{},
+		SubjectCalculator: wmnats.DefaultSubjectCalculator,
+		JetStream:         jetStreamConfig,
+	}, logger)
+	if err != nil {
+		_ = subscriber.Close()
+		conn.Close()
+		return nil, err
+	}
+
+	return &Broker{
+		conn:       conn,
+		subscriber: subscriber,
+		publisher:  publisher,
+	}, nil
+}
+
+// ensureStream creates the stream unless it exists. An existing stream is
+// used as is, so its subjects and retention can be managed outside the service.
+func ensureStream(conn *natsgo.Conn, name string) error {
+	js, err := conn.JetStream()
+	if err != nil {
+		return err
+	}
+
+	_, err = js.StreamInfo(name)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, natsgo.ErrStreamNotFound) {
+		return fmt.Errorf("failed to get stream %s: %w", name, err)
+	}
+
+	_, err = js.AddStream(&natsgo.StreamConfig{
+		Name:     name,
+		Subjects: []string{streamSubjects},
+		Storage:  natsgo.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create stream %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (b *Broker) GetSubscriber() message.Subscriber {
+	return b.subscriber
+}
+
+func (b *Broker) GetPublisher() message.Publisher {
+	return b.publisher
+}
+
+// Format maps a topic to a NATS subject. The single-level wildcard ('*') is
+// the same in both.
+func (b *Broker) Format(topic string) string {
+	return strings.ReplaceAll(topic, "/", ".")
+}
+
+func (b *Broker) Close() {
+	_ = b.publisher.Close()
+	_ = b.subscriber.Close()
+	b.conn.Close()
+}
+
+// marshaler keeps metadata in NATS headers, but the content type of messages,
+// which goes in the Content-Type header as the CloudEvents NATS binding does.
+// It also sets the topic of received messages from their subject.
+type marshaler struct {
+	wmnats.NATSMarshaler
+}
+
+func (m *marshaler) Marshal(topic string, msg *message.Message) (*natsgo.Msg, error) {
+	natsMsg, err := m.NATSMarshaler.Marshal(topic, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType := msg.Metadata.Get(codec.MetadataContentType); contentType != "" {
+		natsMsg.Header.Del(codec.MetadataContentType)
+		natsMsg.Header.Set(headerContentType, contentType)
+	}
+
+	return natsMsg, nil
+}
+
+func (m *marshaler) Unmarshal(natsMsg *natsgo.Msg) (*message.Message, error) {
+	msg, err := m.NATSMarshaler.Unmarshal(natsMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType := msg.Metadata.Get(headerContentType); contentType != "" {
+		delete(msg.Metadata, headerContentType)
+		if msg.Metadata.Get(codec.MetadataContentType) == "" {
+			msg.Metadata.Set(codec.MetadataContentType, contentType)
+		}
+	}
+
+	// a header of the same name set by the publisher is not trusted
+	msg.Metadata.Set(codec.MetadataReceivedTopic, strings.ReplaceAll(natsMsg.Subject, ".", "/"))
+
+	return msg, nil
+}
@@ -0,0 +1,241 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input    string
+		expected string
+	}{
+		{
+			name:     "Exact Topic",
+			input:    "entities/1/update",
+			expected: "entities.1.update",
+		},
+		{
+			name:     "Wildcard Topic",
+			input:    "entities/*/commands/*/ack",
+			expected: "entities.*.commands.*.ack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Broker{}).Format(tt.input); got != tt.expected {
+				t.Errorf("Test failed. Expected: %s, Got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	tests := []struct {
+		name string
+
+		subscribeTopic string
+		publishTopic   string
+		wantDelivery   bool
+	}{
+		{
+			name:           "Exact Topic",
+			subscribeTopic: "entities/1/update",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/state",
+			wantDelivery:   true,
+		},
+		{
+			name:           "Wildcard Topic - No Match",
+			subscribeTopic: "entities/*/state",
+			publishTopic:   "entities/1/update",
+			wantDelivery:   false,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupBroker(t)
+			// a consumer per test case, in case they share a server
+			cfg.Consumer = fmt.Sprintf("esr-service-test-%d", i)
+
+			bk, err := NewNATSBroker(cfg)
+			if err != nil {
+				t.Fatalf("failed to create broker: %v", err)
+			}
+			t.Cleanup(bk.Close)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			messages, err := bk.GetSubscriber().Subscribe(ctx, bk.Format(tt.subscribeTopic))
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+
+			msg := message.NewMessage(fmt.Sprintf("msg%d-%d", i, time.Now().UnixNano()), []byte(`{"power": "on"}`))
+			msg.Metadata.Set("content_type", "application/json")
+			// a topic set by the publisher must not be trusted
+			msg.Metadata.Set(codec.MetadataReceivedTopic, "entities/2/state")
+
+			if err := bk.GetPublisher().Publish(bk.Format(tt.publishTopic), msg); err != nil {
+				t.Fatalf("failed to publish: %v", err)
+			}
+
+			select {
+			case got := <-messages:
+				got.Ack()
+				if !tt.wantDelivery {
+					t.Errorf("Test failed. Unexpected message: %s", got.UUID)
+					return
+				}
+
+				if got.UUID != msg.UUID || string(got.Payload) != string(msg.Payload) {
+					t.Errorf("Test failed. Expected: %s %s, Got: %s %s", msg.UUID, msg.Payload, got.UUID, got.Payload)
+				}
+
+				if got.Metadata.Get("content_type") != "application/json" {
+					t.Errorf("Test failed. Expected metadata to be kept, Got: %+v", got.Metadata)
+				}
+
+				if topic := got.Metadata.Get(codec.MetadataReceivedTopic); topic != tt.publishTopic {
+					t.Errorf("Test failed. Expected: %s, Got: %s", tt.publishTopic, topic)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantDelivery {
+					t.Errorf("Test failed. Message was not delivered")
+				}
+			}
+		})
+	}
+}
+
+func TestNewNATSBroker(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputConfig config.BrokerConfig
+	}{
+		{
+			name:        "Error - Missing Stream",
+			inputConfig: config.BrokerConfig{Consumer: "esr-service"},
+		},
+		{
+			name:        "Error - Missing Consumer",
+			inputConfig: config.BrokerConfig{Stream: "esr-service"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNATSBroker(tt.inputConfig); err == nil {
+				t.Errorf("Test failed. Expected an error")
+			}
+		})
+	}
+}
+
+// setupBroker returns the configuration of the NATS server to test against:
+// the one set with ESR_TEST_NATS_HOST and ESR_TEST_NATS_PORT if any, which
+// must have JetStream enabled, or otherwise an embedded server.
+func setupBroker(t *testing.T) config.BrokerConfig {
+	t.Helper()
+
+	cfg := config.BrokerConfig{
+		ClientID: "esr-service-test",
+		Stream:   "esr-service-test",
+	}
+
+	if host := os.Getenv("ESR_TEST_NATS_HOST"); host != "" {
+		port, err := strconv.Atoi(os.Getenv("ESR_TEST_NATS_PORT"))
+		if err != nil {
+			port = 4222
+		}
+
+		cfg.Host = host
+		cfg.Port = port
+		return cfg
+	}
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NATS server: %v", err)
+	}
+
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("NATS server is not ready")
+	}
+
+	addr := ns.Addr().(*net.TCPAddr)
+	cfg.Host = addr.IP.String()
+	cfg.Port = addr.Port
+	return cfg
+}
+
+func TestMarshalerContentType(t *testing.T) {
+	m := marshaler{}
+
+	msg := message.NewMessage("msg1", []byte(`{"power":"on"}`))
+	msg.Metadata.Set(codec.MetadataContentType, codec.ContentTypeJSON)
+
+	natsMsg, err := m.Marshal("entities.1.update", msg)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if contentType := natsMsg.Header.Get("Content-Type"); contentType != codec.ContentTypeJSON {
+		t.Errorf("Test failed. Expected: %s, Got: %s", codec.ContentTypeJSON, contentType)
+	}
+
+	if header := natsMsg.Header.Get(codec.MetadataContentType); header != "" {
+		t.Errorf("Test failed. Unexpected %s header: %s", codec.MetadataContentType, header)
+	}
+
+	// messages of other producers may only have the header
+	got, err := m.Unmarshal(&natsgo.Msg{
+		Subject: "entities.1.state",
+		Header:  natsgo.Header{"Content-Type": []string{codec.ContentTypeCloudEventsJSON}},
+		Data:    natsMsg.Data,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if contentType := got.Metadata.Get(codec.MetadataContentType); contentType != codec.ContentTypeCloudEventsJSON {
+		t.Errorf("Test failed. Expected: %s, Got: %s", codec.ContentTypeCloudEventsJSON, contentType)
+	}
+
+	if _, ok := got.Metadata["Content-Type"]; ok {
+		t.Errorf("Test failed. Unexpected metadata: %+v", got.Metadata)
+	}
+}
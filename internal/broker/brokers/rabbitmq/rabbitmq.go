@@ -1,32 +1,51 @@
 package rabbitmq
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
 	"github.com/pmoura-dev/esr-service/internal/config"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill-amqp/v3/pkg/amqp"
 	"github.com/ThreeDotsLabs/watermill/message"
+	amqp091 "github.com/rabbitmq/amqp091-go"
 )
 
 const (
 	Name = "rabbitmq"
 
-	exchangeName = "esr-service"
-	queueName    = "esr-service-queue"
+	// QueueNamingGroup gives every consumer group its own queue per topic, so
+	// each group receives every message, and instances of a group share them.
+	QueueNamingGroup = "group"
+	// QueueNamingTopic names queues after their topic, so every consumer of a
+	// topic shares its messages, whatever its group.
+	QueueNamingTopic = "topic"
 )
 
+// exchangeTypes are the kinds of exchange the service can use. Subscriptions
+// bind queues with wildcards, e.g. 'entities.*.state', which only topic
+// exchanges match: a direct exchange would never route device messages, and a
+// fanout or headers one would route every message to every queue.
+var exchangeTypes = map[string]struct{}{
+	"topic": {},
+}
+
 type Broker struct {
 	subscriber *amqp.Subscriber
 	publisher  *amqp.Publisher
 }
 
 func NewRabbitMQBroker(config config.BrokerConfig) (*Broker, error) {
-	amqpURI := fmt.Sprintf("amqp://%s:%s@%s:%d/", config.Username, config.Password, config.Host, config.Port)
-
-	amqpConfig := amqp.NewDurableTopicConfig(amqpURI, exchangeName, queueName)
+	amqpConfig, err := newAMQPConfig(config)
+	if err != nil {
+		return nil, err
+	}
 
 	subscriber, err := amqp.NewSubscriber(amqpConfig, watermill.NewSlogLogger(nil))
 	if err != nil {
@@ -35,6 +54,7 @@ func NewRabbitMQBroker(config config.BrokerConfig) (*Broker, error) {
 
 	publisher, err := amqp.NewPublisher(amqpConfig, watermill.NewSlogLogger(nil))
 	if err != nil {
+		_ = subscriber.Close()
 		return nil, err
 	}
 
@@ -44,6 +64,66 @@ func NewRabbitMQBroker(config config.BrokerConfig) (*Broker, error) {
 	}, nil
 }
 
+func newAMQPConfig(config config.BrokerConfig) (amqp.Config, error) {
+	if config.Exchange == "" {
+		return amqp.Config{}, errors.New("an exchange name is required")
+	}
+
+	if _, ok := exchangeTypes[config.ExchangeType]; !ok {
+		return amqp.Config{}, fmt.Errorf("unsupported exchange type: %s, only 'topic' exchanges match wildcard topics", config.ExchangeType)
+	}
+
+	var generateQueueName amqp.QueueNameGenerator
+	switch config.QueueNaming {
+	case QueueNamingGroup:
+		if config.Consumer == "" {
+			return amqp.Config{}, errors.New("a consumer group is required to name queues by group")
+		}
+		generateQueueName = amqp.GenerateQueueNameTopicNameWithSuffix(config.Consumer)
+	case QueueNamingTopic:
+		generateQueueName = amqp.GenerateQueueNameTopicName
+	default:
+		return amqp.Config{}, fmt.Errorf("unknown queue naming strategy: %s", config.QueueNaming)
+	}
+
+	tlsConfig, err := config.TLS.ClientConfig()
+	if err != nil {
+		return amqp.Config{}, err
+	}
+
+	scheme := "amqp"
+	if tlsConfig != nil {
+		scheme = "amqps"
+	}
+
+	amqpURI := url.URL{
+		Scheme: scheme,
+		User:   url.UserPassword(config.Username, config.Password),
+		Host:   net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		// the default virtual host, '/', has to be escaped
+		Path:    "/" + config.VHost,
+		RawPath: "/" + url.PathEscape(config.VHost),
+	}
+
+	newConfig := amqp.NewNonDurableTopicConfig
+	if config.Durable {
+		newConfig = amqp.NewDurableTopicConfig
+	}
+
+	amqpConfig := newConfig(amqpURI.String(), config.Exchange, "")
+	amqpConfig.Connection.TLSConfig = tlsConfig
+	amqpConfig.Marshaler = marshaler{
+		DefaultMarshaler: amqp.DefaultMarshaler{NotPersistentDeliveryMode: !config.Durable},
+	}
+	amqpConfig.Exchange.Type = config.ExchangeType
+	// every subscribed topic gets its own queue, so handlers never consume
+	// each other's messages
+	amqpConfig.Queue.GenerateName = generateQueueName
+	amqpConfig.Consume.Qos.PrefetchCount = config.Prefetch
+
+	return amqpConfig, nil
+}
+
 func (b *Broker) GetSubscriber() message.Subscriber {
 	return b.subscriber
 }
@@ -60,3 +140,37 @@ func (b *Broker) Close() {
 	_ = b.publisher.Close()
 	_ = b.subscriber.Close()
 }
+
+// marshaler carries the content type of messages in the AMQP content-type
+// property, as the CloudEvents AMQP binding does, besides the headers. It also
+// sets the topic of received messages from their routing key.
+type marshaler struct {
+	amqp.DefaultMarshaler
+}
+
+func (m marshaler) Marshal(msg *message.Message) (amqp091.Publishing, error) {
+	publishing, err := m.DefaultMarshaler.Marshal(msg)
+	if err != nil {
+		return amqp091.Publishing{}, err
+	}
+
+	publishing.ContentType = msg.Metadata.Get(codec.MetadataContentType)
+
+	return publishing, nil
+}
+
+func (m marshaler) Unmarshal(delivery amqp091.Delivery) (*message.Message, error) {
+	msg, err := m.DefaultMarshaler.Unmarshal(delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Metadata.Get(codec.MetadataContentType) == "" && delivery.ContentType != "" {
+		msg.Metadata.Set(codec.MetadataContentType, delivery.ContentType)
+	}
+
+	// a header of the same name set by the publisher is not trusted
+	msg.Metadata.Set(codec.MetadataReceivedTopic, strings.ReplaceAll(delivery.RoutingKey, ".", "/"))
+
+	return msg, nil
+}
@@ -0,0 +1,325 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	NameCloudEventsStructured = "cloudevents-structured"
+	NameCloudEventsBinary     = "cloudevents-binary"
+
+	ContentTypeCloudEventsJSON = "application/cloudevents+json"
+
+	cloudEventsSpecVersion = "1.0"
+)
+
+// CloudEventsBinding is how the protocol of a broker carries the attributes of
+// binary mode events in message metadata.
+type CloudEventsBinding struct {
+	// Prefixes are the prefixes of attribute names. Events are encoded with
+	// the first one, and decoded with any of them.
+	Prefixes []string
+}
+
+var (
+	// CloudEventsBindingKafka is also used by the brokers without a binding
+	// of their own.
+	CloudEventsBindingKafka = CloudEventsBinding{Prefixes: []string{"ce_"}}
+	// CloudEventsBindingAMQP keeps the 'cloudEvents:' prefix for the producers
+	// of older versions of the binding.
+	CloudEventsBindingAMQP = CloudEventsBinding{Prefixes: []string{"cloudEvents_", "cloudEvents:"}}
+	CloudEventsBindingNATS = CloudEventsBinding{Prefixes: []string{"ce-"}}
+)
+
+// CloudEvents attribute names
+const (
+	attributeSpecVersion   = "specversion"
+	attributeID            = "id"
+	attributeSource        = "source"
+	attributeType          = "type"
+	attributeSubject       = "subject"
+	attributeTime          = "time"
+	attributeCorrelationID = "correlationid"
+)
+
+type CloudEventsMode int
+
+const (
+	// CloudEventsStructured encodes the whole event as JSON in the payload.
+	CloudEventsStructured CloudEventsMode = iota
+	// CloudEventsBinary keeps the data as the payload and the attributes in the metadata.
+	CloudEventsBinary
+)
+
+// CloudEventsCodec encodes events as CloudEvents 1.0, with the attributes of
+// binary mode events named after the binding of the broker. It decodes both modes,
+// telling them apart by content type, whatever mode it encodes in. Messages
+// without any metadata, e.g. received over MQTT 3.1.1, are decoded as
+// structured mode events when their payload has a 'specversion'.
+type CloudEventsCodec struct {
+	source  string
+	mode    CloudEventsMode
+	binding CloudEventsBinding
+}
+
+func NewCloudEventsCodec(source string, mode CloudEventsMode, binding CloudEventsBinding) *CloudEventsCodec {
+	return &CloudEventsCodec{
+		source:  source,
+		mode:    mode,
+		binding: binding,
+	}
+}
+
+// cloudEvent is the JSON format of a structured mode event.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      []byte          `json:"data_base64,omitempty"`
+}
+
+func (c *CloudEventsCodec) Encode(event Event) (*message.Message, error) {
+	if event.Source == "" {
+		event.Source = c.source
+	}
+
+	if event.ContentType == "" {
+		event.ContentType = ContentTypeJSON
+	}
+
+	if err := validateEvent(event); err != nil {
+		return nil, err
+	}
+
+	var msg *message.Message
+
+	switch c.mode {
+	case CloudEventsBinary:
+		msg = message.NewMessage(event.ID, event.Data)
+		c.setAttribute(msg, attributeSpecVersion, cloudEventsSpecVersion)
+		c.setAttribute(msg, attributeID, event.ID)
+		c.setAttribute(msg, attributeSource, event.Source)
+		c.setAttribute(msg, attributeType, event.Type)
+		c.setAttribute(msg, attributeSubject, event.Subject)
+		c.setAttribute(msg, attributeCorrelationID, event.CorrelationID)
+		if !event.Time.IsZero() {
+			c.setAttribute(msg, attributeTime, event.Time.Format(time.RFC3339Nano))
+		}
+		msg.Metadata.Set(MetadataContentType, event.ContentType)
+	default:
+		structured := cloudEvent{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              event.ID,
+			Source:          event.Source,
+			Type:            event.Type,
+			Subject:         event.Subject,
+			DataContentType: event.ContentType,
+			CorrelationID:   event.CorrelationID,
+		}
+
+		if !event.Time.IsZero() {
+			structured.Time = &event.Time
+		}
+
+		if isJSON(event.ContentType) {
+			if !json.Valid(event.Data) {
+				return nil, fmt.Errorf("%w: data is not valid JSON", ErrInvalidEvent)
+			}
+			structured.Data = event.Data
+		} else {
+			structured.DataBase64 = event.Data
+		}
+
+		payload, err := json.Marshal(structured)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+
+		msg = message.NewMessage(event.ID, payload)
+		msg.Metadata.Set(MetadataContentType, ContentTypeCloudEventsJSON)
+	}
+
+	if event.CorrelationID != "" {
+		msg.Metadata.Set(MetadataCorrelationID, event.CorrelationID)
+	}
+
+	return msg, nil
+}
+
+func (c *CloudEventsCodec) Decode(msg *message.Message) (Event, error) {
+	var (
+		event Event
+		err   error
+	)
+
+	switch {
+	case mediaType(msg.Metadata.Get(MetadataContentType)) == ContentTypeCloudEventsJSON:
+		event, err = decodeStructured(msg)
+	case c.getAttribute(msg, attributeSpecVersion) != "":
+		event, err = c.decodeBinary(msg)
+	case msg.Metadata.Get(MetadataContentType) == "" && hasSpecVersion(msg.Payload):
+		event, err = decodeStructured(msg)
+	default:
+		return Event{}, fmt.Errorf("%w: message is not a CloudEvent", ErrInvalidEvent)
+	}
+
+	if err != nil {
+		return Event{}, err
+	}
+
+	if err := validateEvent(event); err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+// hasSpecVersion tells whether a payload is a JSON object with a 'specversion'
+// member, like structured mode events.
+func hasSpecVersion(payload []byte) bool {
+	var structured struct {
+		SpecVersion *string `json:"specversion"`
+	}
+
+	if err := json.Unmarshal(payload, &structured); err != nil {
+		return false
+	}
+
+	return structured.SpecVersion != nil
+}
+
+func decodeStructured(msg *message.Message) (Event, error) {
+	var structured cloudEvent
+	if err := json.Unmarshal(msg.Payload, &structured); err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	if err := checkSpecVersion(structured.SpecVersion); err != nil {
+		return Event{}, err
+	}
+
+	event := Event{
+		ID:            structured.ID,
+		Type:          structured.Type,
+		Source:        structured.Source,
+		Subject:       structured.Subject,
+		ContentType:   structured.DataContentType,
+		CorrelationID: structured.CorrelationID,
+		Data:          structured.Data,
+	}
+
+	if structured.Time != nil {
+		event.Time = *structured.Time
+	}
+
+	if structured.DataBase64 != nil {
+		event.Data = structured.DataBase64
+	}
+
+	if event.ContentType == "" {
+		event.ContentType = ContentTypeJSON
+	}
+
+	return event, nil
+}
+
+func (c *CloudEventsCodec) decodeBinary(msg *message.Message) (Event, error) {
+	if err := checkSpecVersion(c.getAttribute(msg, attributeSpecVersion)); err != nil {
+		return Event{}, err
+	}
+
+	event := Event{
+		ID:            c.getAttribute(msg, attributeID),
+		Type:          c.getAttribute(msg, attributeType),
+		Source:        c.getAttribute(msg, attributeSource),
+		Subject:       c.getAttribute(msg, attributeSubject),
+		ContentType:   msg.Metadata.Get(MetadataContentType),
+		CorrelationID: c.getAttribute(msg, attributeCorrelationID),
+		Data:          msg.Payload,
+	}
+
+	if rawTime := c.getAttribute(msg, attributeTime); rawTime != "" {
+		t, err := time.Parse(time.RFC3339Nano, rawTime)
+		if err != nil {
+			return Event{}, fmt.Errorf("%w: '%s' must be an RFC3339 timestamp", ErrInvalidEvent, attributeTime)
+		}
+		event.Time = t
+	}
+
+	if event.ContentType == "" {
+		event.ContentType = ContentTypeJSON
+	}
+
+	return event, nil
+}
+
+func checkSpecVersion(specVersion string) error {
+	if specVersion != cloudEventsSpecVersion {
+		return fmt.Errorf("%w: unsupported spec version '%s'", ErrInvalidEvent, specVersion)
+	}
+
+	return nil
+}
+
+// validateEvent checks the attributes CloudEvents requires.
+func validateEvent(event Event) error {
+	required := []struct {
+		attribute string
+		value     string
+	}{
+		{attributeID, event.ID},
+		{attributeSource, event.Source},
+		{attributeType, event.Type},
+	}
+
+	for _, r := range required {
+		if r.value == "" {
+			return fmt.Errorf("%w: '%s' is required", ErrInvalidEvent, r.attribute)
+		}
+	}
+
+	return nil
+}
+
+func (c *CloudEventsCodec) setAttribute(msg *message.Message, attribute string, value string) {
+	if value != "" && len(c.binding.Prefixes) > 0 {
+		msg.Metadata.Set(c.binding.Prefixes[0]+attribute, value)
+	}
+}
+
+func (c *CloudEventsCodec) getAttribute(msg *message.Message, attribute string) string {
+	for _, prefix := range c.binding.Prefixes {
+		if value := msg.Metadata.Get(prefix + attribute); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// mediaType returns the content type without its parameters, e.g. the charset.
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	return mediaType
+}
+
+func isJSON(contentType string) bool {
+	mediaType := mediaType(contentType)
+	return mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,298 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestCloudEventsRoundTrip(t *testing.T) {
+	event := Event{
+		ID:            "1",
+		Type:          "esr.command.issued",
+		Subject:       "lamp-1",
+		Time:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		ContentType:   ContentTypeJSON,
+		CorrelationID: "cmd1",
+		Data:          []byte(`{"power":"on"}`),
+	}
+
+	expected := event
+	expected.Source = "esr-service"
+
+	tests := []struct {
+		name string
+
+		inputMode           CloudEventsMode
+		inputBinding        CloudEventsBinding
+		inputEvent          Event
+		expected            Event
+		expectedContentType string
+		expectedMetadataKey string
+	}{
+		{
+			name:                "Structured",
+			inputMode:           CloudEventsStructured,
+			inputBinding:        CloudEventsBindingKafka,
+			inputEvent:          event,
+			expected:            expected,
+			expectedContentType: ContentTypeCloudEventsJSON,
+		},
+		{
+			name:                "Binary",
+			inputMode:           CloudEventsBinary,
+			inputBinding:        CloudEventsBindingKafka,
+			inputEvent:          event,
+			expected:            expected,
+			expectedContentType: ContentTypeJSON,
+			expectedMetadataKey: "ce_specversion",
+		},
+		{
+			name:                "Binary - AMQP",
+			inputMode:           CloudEventsBinary,
+			inputBinding:        CloudEventsBindingAMQP,
+			inputEvent:          event,
+			expected:            expected,
+			expectedContentType: ContentTypeJSON,
+			expectedMetadataKey: "cloudEvents_specversion",
+		},
+		{
+			name:                "Binary - NATS",
+			inputMode:           CloudEventsBinary,
+			inputBinding:        CloudEventsBindingNATS,
+			inputEvent:          event,
+			expected:            expected,
+			expectedContentType: ContentTypeJSON,
+			expectedMetadataKey: "ce-specversion",
+		},
+		{
+			name:         "Structured - Binary Data",
+			inputMode:    CloudEventsStructured,
+			inputBinding: CloudEventsBindingKafka,
+			inputEvent: Event{
+				ID:          "2",
+				Type:        "esr.test",
+				Source:      "test",
+				ContentType: "application/octet-stream",
+				Data:        []byte{0x00, 0xff},
+			},
+			expected: Event{
+				ID:          "2",
+				Type:        "esr.test",
+				Source:      "test",
+				ContentType: "application/octet-stream",
+				Data:        []byte{0x00, 0xff},
+			},
+			expectedContentType: ContentTypeCloudEventsJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCloudEventsCodec("esr-service", tt.inputMode, tt.inputBinding)
+
+			msg, err := c.Encode(tt.inputEvent)
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if contentType := msg.Metadata.Get(MetadataContentType); contentType != tt.expectedContentType {
+				t.Errorf("Test failed. Expected content type: %s, Got: %s", tt.expectedContentType, contentType)
+			}
+
+			if msg.Metadata.Get(MetadataCorrelationID) != tt.inputEvent.CorrelationID {
+				t.Errorf("Test failed. Expected correlation ID: %s, Got: %+v", tt.inputEvent.CorrelationID, msg.Metadata)
+			}
+
+			if tt.expectedMetadataKey != "" && msg.Metadata.Get(tt.expectedMetadataKey) == "" {
+				t.Errorf("Test failed. Expected metadata key: %s, Got: %+v", tt.expectedMetadataKey, msg.Metadata)
+			}
+
+			// both modes are decoded whatever the mode of the decoding codec
+			for _, mode := range []CloudEventsMode{CloudEventsStructured, CloudEventsBinary} {
+				got, err := NewCloudEventsCodec("other", mode, tt.inputBinding).Decode(msg)
+				if err != nil {
+					t.Errorf("Test failed. Unexpected error: %v", err)
+					return
+				}
+
+				if !reflect.DeepEqual(tt.expected, got) {
+					t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCloudEventsStructuredFormat(t *testing.T) {
+	c := NewCloudEventsCodec("esr-service", CloudEventsStructured, CloudEventsBindingKafka)
+
+	msg, err := c.Encode(Event{
+		ID:          "1",
+		Type:        "esr.command.issued",
+		ContentType: ContentTypeJSON,
+		Data:        []byte(`{"power":"on"}`),
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(msg.Payload, &got); err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	expected := map[string]any{
+		"specversion":     "1.0",
+		"id":              "1",
+		"source":          "esr-service",
+		"type":            "esr.command.issued",
+		"datacontenttype": "application/json",
+		"data":            map[string]any{"power": "on"},
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+	}
+}
+
+func TestCloudEventsDecode(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputPayload  string
+		inputMetadata message.Metadata
+		inputBinding  *CloudEventsBinding
+		expected      Event
+		wantErr       bool
+	}{
+		{
+			name:         "Structured - Content Type Parameters",
+			inputPayload: `{"specversion":"1.0","id":"1","source":"device","type":"esr.state.reported","data":{"power":"on"}}`,
+			inputMetadata: message.Metadata{
+				MetadataContentType: "application/cloudevents+json; charset=utf-8",
+			},
+			expected: Event{
+				ID:          "1",
+				Source:      "device",
+				Type:        "esr.state.reported",
+				ContentType: ContentTypeJSON,
+				Data:        []byte(`{"power":"on"}`),
+			},
+		},
+		{
+			name:         "Structured - No Content Type",
+			inputPayload: `{"specversion":"1.0","id":"1","source":"device","type":"esr.state.reported","data":{"power":"on"}}`,
+			expected: Event{
+				ID:          "1",
+				Source:      "device",
+				Type:        "esr.state.reported",
+				ContentType: ContentTypeJSON,
+				Data:        []byte(`{"power":"on"}`),
+			},
+		},
+		{
+			name:         "Binary - AMQP Colon Prefix",
+			inputPayload: `{"power":"on"}`,
+			inputMetadata: message.Metadata{
+				"cloudEvents:specversion": "1.0",
+				"cloudEvents:id":          "1",
+				"cloudEvents:source":      "device",
+				"cloudEvents:type":        "esr.state.reported",
+				MetadataContentType:       ContentTypeJSON,
+			},
+			inputBinding: &CloudEventsBindingAMQP,
+			expected: Event{
+				ID:          "1",
+				Source:      "device",
+				Type:        "esr.state.reported",
+				ContentType: ContentTypeJSON,
+				Data:        []byte(`{"power":"on"}`),
+			},
+		},
+		{
+			name:         "Error - Prefix Of Another Binding",
+			inputPayload: `{"power":"on"}`,
+			inputMetadata: message.Metadata{
+				"ce_specversion": "1.0",
+				"ce_id":          "1",
+				"ce_source":      "device",
+				"ce_type":        "esr.state.reported",
+			},
+			inputBinding: &CloudEventsBindingAMQP,
+			wantErr:      true,
+		},
+		{
+			name:         "Error - Not A CloudEvent",
+			inputPayload: `{"power":"on"}`,
+			wantErr:      true,
+		},
+		{
+			name:         "Error - Unsupported Spec Version",
+			inputPayload: `{"specversion":"0.3","id":"1","source":"device","type":"esr.state.reported"}`,
+			inputMetadata: message.Metadata{
+				MetadataContentType: ContentTypeCloudEventsJSON,
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Error - Missing Type",
+			inputPayload: `{"power":"on"}`,
+			inputMetadata: message.Metadata{
+				"ce_specversion": "1.0",
+				"ce_id":          "1",
+				"ce_source":      "device",
+			},
+			wantErr: true,
+		},
+		{
+			name:         "Error - Invalid Time",
+			inputPayload: `{"power":"on"}`,
+			inputMetadata: message.Metadata{
+				"ce_specversion": "1.0",
+				"ce_id":          "1",
+				"ce_source":      "device",
+				"ce_type":        "esr.state.reported",
+				"ce_time":        "yesterday",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := message.NewMessage("1", []byte(tt.inputPayload))
+			for key, value := range tt.inputMetadata {
+				msg.Metadata.Set(key, value)
+			}
+
+			binding := CloudEventsBindingKafka
+			if tt.inputBinding != nil {
+				binding = *tt.inputBinding
+			}
+
+			got, err := NewCloudEventsCodec("esr-service", CloudEventsStructured, binding).Decode(msg)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEvent) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", ErrInvalidEvent, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
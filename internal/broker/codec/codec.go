@@ -0,0 +1,84 @@
+// Package codec converts events to and from watermill messages, so services
+// and handlers do not depend on how messages look on the wire.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+)
+
+// Metadata keys set on every encoded message, whatever the codec. Brokers
+// with a content type of their own, the AMQP content-type property or the
+// NATS Content-Type header, carry MetadataContentType there.
+const (
+	// MetadataCorrelationID relates a message to the record it is about, e.g.
+	// the command ID, so replies can be matched to it.
+	MetadataCorrelationID = middleware.CorrelationIDMetadataKey
+	MetadataContentType   = "content_type"
+)
+
+// MetadataReceivedTopic is set by every broker on inbound messages to the
+// topic the message was published to, in the format of the service, e.g.
+// 'entities/1/state'. A subscription to a topic with wildcards receives
+// messages from many topics. Brokers overwrite any value sent by publishers.
+const MetadataReceivedTopic = "received_topic"
+
+const (
+	ContentTypeJSON = "application/json"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is a message independent of its encoding. Its attributes follow the
+// CloudEvents 1.0 context attributes.
+type Event struct {
+	ID string
+	// Type describes the event, e.g. 'esr.command.issued'.
+	Type string
+	// Source identifies the producer, codecs fill it in when empty.
+	Source  string
+	Subject string
+	Time    time.Time
+	// ContentType is the media type of Data.
+	ContentType   string
+	CorrelationID string
+	Data          []byte
+}
+
+type Codec interface {
+	Encode(event Event) (*message.Message, error)
+	// Decode returns ErrInvalidEvent when the message is not a valid event.
+	Decode(msg *message.Message) (Event, error)
+}
+
+func GetCodec(config config.BrokerConfig) (Codec, error) {
+	switch config.Codec {
+	case NameJSON:
+		return NewJSONCodec(), nil
+	case NameCloudEventsStructured:
+		return NewCloudEventsCodec(config.EventSource, CloudEventsStructured, cloudEventsBinding(config.BrokerType)), nil
+	case NameCloudEventsBinary:
+		return NewCloudEventsCodec(config.EventSource, CloudEventsBinary, cloudEventsBinding(config.BrokerType)), nil
+	default:
+		return nil, fmt.Errorf("unknown codec: %s", config.Codec)
+	}
+}
+
+// cloudEventsBinding returns the CloudEvents binding of the protocol of a
+// broker type. The broker names are repeated, brokers import this package.
+func cloudEventsBinding(brokerType string) CloudEventsBinding {
+	switch brokerType {
+	case "rabbitmq":
+		return CloudEventsBindingAMQP
+	case "nats":
+		return CloudEventsBindingNATS
+	default:
+		return CloudEventsBindingKafka
+	}
+}
@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	NameJSON = "json"
+)
+
+// JSONCodec sends the event data as is. The other attributes are lost, except
+// for the correlation ID and content type, which are kept as metadata.
+type JSONCodec struct{}
+
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{}
+}
+
+func (c *JSONCodec) Encode(event Event) (*message.Message, error) {
+	msg := message.NewMessage(event.ID, event.Data)
+
+	if event.CorrelationID != "" {
+		msg.Metadata.Set(MetadataCorrelationID, event.CorrelationID)
+	}
+
+	contentType := event.ContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+	msg.Metadata.Set(MetadataContentType, contentType)
+
+	return msg, nil
+}
+
+func (c *JSONCodec) Decode(msg *message.Message) (Event, error) {
+	contentType := msg.Metadata.Get(MetadataContentType)
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	return Event{
+		ID:            msg.UUID,
+		ContentType:   contentType,
+		CorrelationID: msg.Metadata.Get(MetadataCorrelationID),
+		Data:          msg.Payload,
+	}, nil
+}
@@ -1,13 +1,18 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
 	DataStore DataStoreConfig
 	Broker    BrokerConfig
+	Command   CommandConfig
+	Outbox    OutboxConfig
+	Retry     RetryConfig
 }
 
 type DataStoreConfig struct {
@@ -18,6 +23,10 @@ type DataStoreConfig struct {
 	Username string
 	Password string
 	Name     string
+
+	// ReadOnly opens the datastore for reading only, which BoltDB needs to be
+	// opened by several processes at once.
+	ReadOnly bool
 }
 
 type BrokerConfig struct {
@@ -26,29 +35,175 @@ type BrokerConfig struct {
 	Port       int
 	Username   string
 	Password   string
+
+	// Codec is the message encoding, e.g. 'json' or 'cloudevents-structured'.
+	Codec string
+	// EventSource is the source attribute of the events the service produces.
+	EventSource string
+
+	// ClientID identifies the service to brokers that track their clients, e.g. MQTT.
+	ClientID string
+	// QoS is the MQTT quality of service used to publish and subscribe.
+	QoS int
+	// CleanSession discards the broker session, and any messages queued in it, on reconnection.
+	CleanSession bool
+
+	// Stream is the JetStream stream storing the messages of the service.
+	Stream string
+	// Consumer is the consumer group of the service: instances in the same
+	// group share the messages of a topic. It prefixes the durable consumers of
+	// JetStream and suffixes the queues of RabbitMQ.
+	Consumer string
+
+	// VHost is the RabbitMQ virtual host.
+	VHost string
+	// Exchange is the RabbitMQ exchange messages are published to.
+	Exchange string
+	// ExchangeType is the kind of exchange, only 'topic' is supported since
+	// subscriptions use wildcards.
+	ExchangeType string
+	// QueueNaming is how RabbitMQ queues are named: 'group' gives each consumer
+	// group its own queue per topic, 'topic' shares one queue per topic
+	// between every consumer.
+	QueueNaming string
+	// Durable queues survive broker restarts, as do the messages published to them.
+	Durable bool
+	// Prefetch is the number of unacknowledged messages delivered to a consumer.
+	Prefetch int
+
+	TLS TLSConfig
+}
+
+type CommandConfig struct {
+	DefaultTimeout time.Duration
+	ReaperInterval time.Duration
+}
+
+type OutboxConfig struct {
+	RelayInterval time.Duration
+	BatchSize     int
+	// Retention is how long sent messages are kept before being purged.
+	Retention time.Duration
+	// ClaimTimeout is how long a message claimed by an instance of the service
+	// is left alone by the others, it must be longer than publishing a batch.
+	ClaimTimeout time.Duration
+}
+
+// RetryConfig sets how inbound messages are retried before being dead-lettered.
+// The interval between attempts doubles, up to MaxInterval.
+type RetryConfig struct {
+	MaxRetries      int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
 }
 
 func LoadConfig() *Config {
 	dbConfig := DataStoreConfig{
 		DataStoreType: getEnvWithDefault("ESR_DATASTORE_TYPE", "boltdb"),
 		Host:          getEnv("ESR_DATASTORE_HOST"),
-		Port:          getIntEnv("EST_DATASTORE_PORT"),
+		Port:          getIntEnv("ESR_DATASTORE_PORT"),
 		Username:      getEnv("ESR_DATASTORE_USERNAME"),
 		Password:      getEnv("ESR_DATASTORE_PASSWORD"),
 		Name:          getEnvWithDefault("ESR_DATABASE_NAME", "esrdb"),
 	}
 
+	brokerType := getEnvWithDefault("ESR_BROKER_TYPE", "rabbitmq")
+	brokerTLS := getBoolEnvWithDefault("ESR_BROKER_TLS_ENABLED", false)
+
 	brokerConfig := BrokerConfig{
-		BrokerType: getEnvWithDefault("ESR_BROKER_TYPE", "rabbitmq"),
+		BrokerType: brokerType,
 		Host:       getEnvWithDefault("ESR_BROKER_HOST", "localhost"),
-		Port:       getIntEnvWithDefault("ESR_BROKER_PORT", 5672),
+		Port:       getIntEnvWithDefault("ESR_BROKER_PORT", defaultBrokerPort(brokerType, brokerTLS)),
 		Username:   getEnvWithDefault("ESR_BROKER_USERNAME", "guest"),
 		Password:   getEnvWithDefault("ESR_BROKER_PASSWORD", "guest"),
+
+		Codec:       getEnvWithDefault("ESR_BROKER_CODEC", "json"),
+		EventSource: getEnvWithDefault("ESR_BROKER_EVENT_SOURCE", "esr-service"),
+
+		ClientID:     getEnvWithDefault("ESR_BROKER_CLIENT_ID", "esr-service"),
+		QoS:          getIntEnvWithDefault("ESR_BROKER_QOS", 1),
+		CleanSession: getBoolEnvWithDefault("ESR_BROKER_CLEAN_SESSION", false),
+
+		Stream:   getEnvWithDefault("ESR_BROKER_STREAM", "esr-service"),
+		Consumer: getEnvWithDefault("ESR_BROKER_CONSUMER", "esr-service"),
+
+		VHost:        getEnvWithDefault("ESR_BROKER_VHOST", "/"),
+		Exchange:     getEnvWithDefault("ESR_BROKER_EXCHANGE", "esr-service"),
+		ExchangeType: getEnvWithDefault("ESR_BROKER_EXCHANGE_TYPE", "topic"),
+		QueueNaming:  getEnvWithDefault("ESR_BROKER_QUEUE_NAMING", "group"),
+		Durable:      getBoolEnvWithDefault("ESR_BROKER_DURABLE", true),
+		Prefetch:     getIntEnvWithDefault("ESR_BROKER_PREFETCH", 1),
+
+		TLS: TLSConfig{
+			Enabled:            brokerTLS,
+			CAFile:             getEnv("ESR_BROKER_TLS_CA_FILE"),
+			CertFile:           getEnv("ESR_BROKER_TLS_CERT_FILE"),
+			KeyFile:            getEnv("ESR_BROKER_TLS_KEY_FILE"),
+			InsecureSkipVerify: getBoolEnvWithDefault("ESR_BROKER_TLS_INSECURE_SKIP_VERIFY", false),
+		},
+	}
+
+	commandConfig := CommandConfig{
+		DefaultTimeout: getDurationEnvWithDefault("ESR_COMMAND_DEFAULT_TIMEOUT", 30*time.Second),
+		ReaperInterval: getDurationEnvWithDefault("ESR_COMMAND_REAPER_INTERVAL", 5*time.Second),
+	}
+
+	outboxConfig := OutboxConfig{
+		RelayInterval: getDurationEnvWithDefault("ESR_OUTBOX_RELAY_INTERVAL", time.Second),
+		BatchSize:     getIntEnvWithDefault("ESR_OUTBOX_BATCH_SIZE", 100),
+		Retention:     getDurationEnvWithDefault("ESR_OUTBOX_RETENTION", 24*time.Hour),
+		ClaimTimeout:  getDurationEnvWithDefault("ESR_OUTBOX_CLAIM_TIMEOUT", 30*time.Second),
+	}
+
+	retryConfig := RetryConfig{
+		MaxRetries:      getIntEnvWithDefault("ESR_RETRY_MAX_RETRIES", 3),
+		InitialInterval: getDurationEnvWithDefault("ESR_RETRY_INITIAL_INTERVAL", 100*time.Millisecond),
+		MaxInterval:     getDurationEnvWithDefault("ESR_RETRY_MAX_INTERVAL", 10*time.Second),
 	}
 
 	return &Config{
 		DataStore: dbConfig,
 		Broker:    brokerConfig,
+		Command:   commandConfig,
+		Outbox:    outboxConfig,
+		Retry:     retryConfig,
+	}
+}
+
+// Validate checks the settings the service can not run with, e.g. the
+// interval of a worker, which must be positive.
+func (c *Config) Validate() error {
+	intervals := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"ESR_COMMAND_REAPER_INTERVAL", c.Command.ReaperInterval},
+		{"ESR_OUTBOX_RELAY_INTERVAL", c.Outbox.RelayInterval},
+	}
+
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("invalid config: %s must be positive, got %s", interval.key, interval.value)
+		}
+	}
+
+	return nil
+}
+
+// defaultBrokerPort returns the standard port of a broker type, which for
+// some depends on whether TLS is used.
+func defaultBrokerPort(brokerType string, tls bool) int {
+	switch {
+	case brokerType == "mqtt" && tls:
+		return 8883
+	case brokerType == "mqtt":
+		return 1883
+	case brokerType == "nats":
+		return 4222
+	case tls:
+		return 5671
+	default:
+		return 5672
 	}
 }
 
@@ -84,3 +239,23 @@ func getIntEnvWithDefault(key string, fallback int) int {
 
 	return i
 }
+
+func getBoolEnvWithDefault(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+
+	return b
+}
+
+func getDurationEnvWithDefault(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+
+	return d
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputReaperInterval time.Duration
+		inputRelayInterval  time.Duration
+		wantErr             bool
+	}{
+		{
+			name:                "Success",
+			inputReaperInterval: 5 * time.Second,
+			inputRelayInterval:  time.Second,
+		},
+		{
+			name:                "Error - Zero Reaper Interval",
+			inputReaperInterval: 0,
+			inputRelayInterval:  time.Second,
+			wantErr:             true,
+		},
+		{
+			name:                "Error - Negative Relay Interval",
+			inputReaperInterval: 5 * time.Second,
+			inputRelayInterval:  -time.Second,
+			wantErr:             true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Command: CommandConfig{ReaperInterval: tt.inputReaperInterval},
+				Outbox:  OutboxConfig{RelayInterval: tt.inputRelayInterval},
+			}
+
+			err := cfg.Validate()
+
+			if tt.wantErr && err == nil {
+				t.Errorf("Test failed. Expected an error")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package boltdb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/pmoura-dev/esr-service/internal/config"
 	"github.com/pmoura-dev/esr-service/internal/datastore"
@@ -12,6 +16,10 @@ const (
 	Name = "boltdb"
 )
 
+// openTimeout is how long opening the file waits for the lock held by another
+// process, e.g. the service while running migrations.
+var openTimeout = 5 * time.Second
+
 // DataStore represents a Bolt datastore
 type DataStore struct {
 	db *bbolt.DB
@@ -19,8 +27,21 @@ type DataStore struct {
 
 func NewBoltDBDataStore(config config.DataStoreConfig) (*DataStore, error) {
 	path := fmt.Sprintf("%s.db", config.Name)
-	db, err := bbolt.Open(path, 0666, nil)
+
+	// bbolt can not create the file when opening it for reading only
+	if _, err := os.Stat(path); config.ReadOnly && errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s does not exist", datastore.ErrConnectionFailed, path)
+	}
+
+	db, err := bbolt.Open(path, 0666, &bbolt.Options{
+		Timeout:  openTimeout,
+		ReadOnly: config.ReadOnly,
+	})
 	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, fmt.Errorf("%w: %s", datastore.ErrDataStoreInUse, path)
+		}
+
 		return nil, datastore.ErrConnectionFailed
 	}
 
@@ -30,3 +51,40 @@ func NewBoltDBDataStore(config config.DataStoreConfig) (*DataStore, error) {
 func (s *DataStore) Close() {
 	_ = s.db.Close()
 }
+
+// keysByEntityID returns a copy of the keys of every record in bucket that
+// belongs to entityID.
+func keysByEntityID(bucket *bbolt.Bucket, entityID string) ([][]byte, error) {
+	var keys [][]byte
+
+	err := bucket.ForEach(func(key, data []byte) error {
+		var record struct {
+			EntityID string `json:"entity_id"`
+		}
+
+		if err := json.Unmarshal(data, &record); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if record.EntityID == entityID {
+			keys = append(keys, append([]byte{}, key...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func deleteKeys(bucket *bbolt.Bucket, keys [][]byte) error {
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return datastore.ErrTransactionFailed
+		}
+	}
+
+	return nil
+}
@@ -1,8 +1,14 @@
 package boltdb
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
 
 	"go.etcd.io/bbolt"
 )
@@ -21,10 +27,20 @@ func setupMockDB(t *testing.T, bucket string, pairs map[string]string) *bbolt.DB
 		t.Fatalf("failed to open bboltDB: %v", err)
 	}
 
-	// Preload data
-	err = db.Update(func(tx *bbolt.Tx) error {
+	// Cleanup after the test
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(tempFile.Name())
+	})
+
+	preloadMockDB(t, db, bucket, pairs)
+
+	return db
+}
 
-		// entities
+// preloadMockDB stores the pairs in bucket, creating it if needed.
+func preloadMockDB(t *testing.T, db *bbolt.DB, bucket string, pairs map[string]string) {
+	err := db.Update(func(tx *bbolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(bucket))
 		if err != nil {
 			return err
@@ -40,12 +56,25 @@ func setupMockDB(t *testing.T, bucket string, pairs map[string]string) *bbolt.DB
 	if err != nil {
 		t.Fatalf("failed to preload mock db: %v", err)
 	}
+}
 
-	// Cleanup after the test
-	t.Cleanup(func() {
-		db.Close()
-		os.Remove(tempFile.Name())
-	})
+func TestNewBoltDBDataStoreInUse(t *testing.T) {
+	openTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { openTimeout = 5 * time.Second })
 
-	return db
+	cfg := config.DataStoreConfig{Name: filepath.Join(t.TempDir(), "esrdb")}
+
+	store, err := NewBoltDBDataStore(cfg)
+	if err != nil {
+		t.Fatalf("failed to open datastore: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	for _, readOnly := range []bool{false, true} {
+		cfg.ReadOnly = readOnly
+
+		if _, err := NewBoltDBDataStore(cfg); !errors.Is(err, datastore.ErrDataStoreInUse) {
+			t.Errorf("Test failed. Expected error: %v, Got: %v", datastore.ErrDataStoreInUse, err)
+		}
+	}
 }
@@ -1,6 +1,10 @@
 package boltdb
 
 import (
+	"strconv"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+
 	"go.etcd.io/bbolt"
 )
 
@@ -8,18 +12,85 @@ const (
 	bucketEntity             = "Entity"
 	bucketCommand            = "Command"
 	bucketReportSubscription = "ReportSubscription"
+	bucketState              = "State"
+	bucketOutbox             = "Outbox"
+	bucketDeadLetter         = "DeadLetter"
+	bucketMeta               = "Meta"
+
+	keySchemaVersion = "schema_version"
 )
 
 func (s *DataStore) Init() error {
-	return s.db.Update(func(tx *bbolt.Tx) error {
-		if _, err := tx.CreateBucketIfNotExists([]byte(bucketEntity)); err != nil {
+	if err := datastore.ValidateMigrations(migrations); err != nil {
+		return err
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range datastore.PendingMigrations(migrations, current) {
+		err := s.db.Update(func(tx *bbolt.Tx) error {
+			if err := migration.Apply(tx); err != nil {
+				return err
+			}
+
+			return setSchemaVersion(tx, migration.Version)
+		})
+
+		if err != nil {
 			return err
 		}
+	}
 
-		if _, err := tx.CreateBucketIfNotExists([]byte(bucketCommand)); err != nil {
-			return err
+	return nil
+}
+
+func (s *DataStore) SchemaVersion() (int, error) {
+	version := 0
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketMeta))
+		if bucket == nil {
+			return nil
 		}
 
+		data := bucket.Get([]byte(keySchemaVersion))
+		if data == nil {
+			return nil
+		}
+
+		v, err := strconv.Atoi(string(data))
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		version = v
 		return nil
 	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (s *DataStore) MigrationStatus() ([]datastore.MigrationStatus, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return datastore.MigrationStatusList(migrations, current), nil
+}
+
+func setSchemaVersion(tx *bbolt.Tx, version int) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(bucketMeta))
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(keySchemaVersion), []byte(strconv.Itoa(version)))
 }
@@ -0,0 +1,112 @@
+package boltdb
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+
+	"go.etcd.io/bbolt"
+)
+
+// listPage returns a page of the records in bucket that match. When the
+// bucket is keyed by ID and the listing is sorted by ID, it seeks the bbolt
+// cursor and stops reading once the page is full. Any other sort loads every
+// matching record and sorts them in memory.
+func listPage[T any](
+	bucket *bbolt.Bucket,
+	ordering datastore.Ordering[T],
+	options datastore.ListOptions,
+	keyedByID bool,
+	match func(T) bool,
+) (datastore.Page[T], error) {
+	options, err := ordering.Normalize(options)
+	if err != nil {
+		return datastore.Page[T]{}, err
+	}
+
+	if !keyedByID || options.Sort.Field != datastore.SortByID {
+		var items []T
+
+		err := bucket.ForEach(func(_, data []byte) error {
+			var item T
+
+			if err := json.Unmarshal(data, &item); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if match(item) {
+				items = append(items, item)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return datastore.Page[T]{}, err
+		}
+
+		return ordering.Paginate(items, options)
+	}
+
+	position, err := ordering.Position(options)
+	if err != nil {
+		return datastore.Page[T]{}, err
+	}
+
+	cursor := bucket.Cursor()
+	descending := options.Sort.Descending
+
+	var items []T
+	for key, data := seek(cursor, position, descending); key != nil; key, data = step(cursor, descending) {
+		var item T
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			return datastore.Page[T]{}, datastore.ErrInvalidData
+		}
+
+		if !match(item) {
+			continue
+		}
+
+		items = append(items, item)
+		if options.Limit > 0 && len(items) > options.Limit {
+			break
+		}
+	}
+
+	return ordering.NewPage(options, items), nil
+}
+
+// seek moves cursor to the first key after position, in the given direction.
+func seek(cursor *bbolt.Cursor, position *datastore.Position, descending bool) ([]byte, []byte) {
+	if position == nil {
+		if descending {
+			return cursor.Last()
+		}
+		return cursor.First()
+	}
+
+	after := []byte(position.ID.(string))
+
+	key, data := cursor.Seek(after)
+	if descending {
+		if key == nil {
+			return cursor.Last()
+		}
+		// Seek lands on the first key >= after, the one before it is below after
+		return cursor.Prev()
+	}
+
+	if key != nil && bytes.Equal(key, after) {
+		return cursor.Next()
+	}
+
+	return key, data
+}
+
+func step(cursor *bbolt.Cursor, descending bool) ([]byte, []byte) {
+	if descending {
+		return cursor.Prev()
+	}
+	return cursor.Next()
+}
@@ -0,0 +1,53 @@
+package boltdb
+
+import (
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+
+	"go.etcd.io/bbolt"
+)
+
+// migrations must only ever be appended to, applied migrations are never run again.
+var migrations = []datastore.Migration[*bbolt.Tx]{
+	{
+		Version:     1,
+		Description: "create entity and command buckets",
+		Apply:       createBuckets(bucketEntity, bucketCommand),
+	},
+	{
+		Version:     2,
+		Description: "create report subscription bucket",
+		Apply:       createBuckets(bucketReportSubscription),
+	},
+	{
+		Version:     3,
+		Description: "create state bucket",
+		Apply:       createBuckets(bucketState),
+	},
+	{
+		Version:     4,
+		Description: "create outbox bucket",
+		Apply:       createBuckets(bucketOutbox),
+	},
+	{
+		Version:     5,
+		Description: "create dead letter bucket",
+		Apply:       createBuckets(bucketDeadLetter),
+	},
+	{
+		Version:     6,
+		Description: "key states by entity",
+		Apply:       keyStatesByEntity,
+	},
+}
+
+func createBuckets(names ...string) func(tx *bbolt.Tx) error {
+	return func(tx *bbolt.Tx) error {
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -39,8 +39,8 @@ func (s *DataStore) GetCommandByID(id string) (types.Command, error) {
 	return command, nil
 }
 
-func (s *DataStore) ListCommands(filter datastore.Filter[types.Command]) ([]types.Command, error) {
-	var commandList []types.Command
+func (s *DataStore) ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error) {
+	var page datastore.Page[types.Command]
 
 	err := s.db.View(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketCommand))
@@ -48,35 +48,31 @@ func (s *DataStore) ListCommands(filter datastore.Filter[types.Command]) ([]type
 			return datastore.ErrTableDoesNotExist
 		}
 
-		return bucket.ForEach(func(_, data []byte) error {
-			var command types.Command
-
-			if err := json.Unmarshal(data, &command); err != nil {
-				return datastore.ErrInvalidData
-			}
-
-			if filter.Check(command) {
-				commandList = append(commandList, command)
-			}
-
-			return nil
+		var err error
+		page, err = listPage(bucket, datastore.CommandOrdering, options, true, func(command types.Command) bool {
+			return filter.Check(command)
 		})
+		return err
 	})
 
 	if err != nil {
-		return nil, err
+		return datastore.Page[types.Command]{}, err
 	}
 
-	return commandList, nil
+	return page, nil
 }
 
-func (s *DataStore) AddCommand(command types.Command) error {
+func (s *DataStore) AddCommand(command types.Command, outbox ...types.OutboxMessage) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketCommand))
 		if bucket == nil {
 			return datastore.ErrTableDoesNotExist
 		}
 
+		if err := entityExists(tx, command.EntityID); err != nil {
+			return err
+		}
+
 		data, err := json.Marshal(command)
 		if err != nil {
 			return datastore.ErrInvalidData
@@ -86,11 +82,11 @@ func (s *DataStore) AddCommand(command types.Command) error {
 			return datastore.ErrTransactionFailed
 		}
 
-		return nil
+		return putOutboxMessages(tx, outbox)
 	})
 }
 
-func (s *DataStore) ResolveCommand(id string, status types.CommandStatus) error {
+func (s *DataStore) ResolveCommand(id string, status types.CommandStatus, reason string) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketCommand))
 		if bucket == nil {
@@ -107,8 +103,13 @@ func (s *DataStore) ResolveCommand(id string, status types.CommandStatus) error
 			return datastore.ErrInvalidData
 		}
 
+		if command.Status != types.CommandStatusPending {
+			return datastore.ErrAlreadyResolved
+		}
+
 		command.Status = status
 		command.ResolvedAt = _data.Ptr(time.Now())
+		command.Reason = reason
 
 		data, err := json.Marshal(command)
 		if err != nil {
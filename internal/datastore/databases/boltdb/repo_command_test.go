@@ -167,6 +167,20 @@ func TestGetAllCommands(t *testing.T) {
 				mockCommand1Pending,
 			},
 		},
+		{
+			name:   "Success - Filter by: Time Before Timeout",
+			bucket: bucketCommand,
+			mocks: map[string]string{
+				"cmd1": _data.MockCommand1Pending,
+				"cmd4": _data.MockCommand2PendingTimeout,
+			},
+			inputFilter: filters.NewCommandFilter().ByTimeBeforeTimeout(
+				time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC),
+			),
+			expected: []types.Command{
+				mockCommand2PendingTimeout,
+			},
+		},
 		{
 			name:        "Error - Table Not Found",
 			bucket:      "test",
@@ -189,7 +203,7 @@ func TestGetAllCommands(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
 			store := DataStore{db: db}
 
-			got, err := store.ListCommands(tt.inputFilter)
+			page, err := store.ListCommands(tt.inputFilter, datastore.ListOptions{})
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -203,8 +217,8 @@ func TestGetAllCommands(t *testing.T) {
 				return
 			}
 
-			if !reflect.DeepEqual(tt.expected, got) {
-				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			if !reflect.DeepEqual(tt.expected, page.Items) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, page.Items)
 			}
 		})
 	}
@@ -212,9 +226,10 @@ func TestGetAllCommands(t *testing.T) {
 
 func TestAddCommand(t *testing.T) {
 	tests := []struct {
-		name   string
-		bucket string
-		mocks  map[string]string
+		name     string
+		bucket   string
+		mocks    map[string]string
+		entities map[string]string
 
 		inputCommand types.Command
 		wantErr      bool
@@ -223,7 +238,16 @@ func TestAddCommand(t *testing.T) {
 		{
 			name:         "Success",
 			bucket:       bucketCommand,
+			entities:     map[string]string{"1": _data.MockEntity1},
+			inputCommand: mockCommand1Pending,
+		},
+		{
+			name:         "Error - Entity Not Found",
+			bucket:       bucketCommand,
+			entities:     map[string]string{"2": _data.MockEntity2},
 			inputCommand: mockCommand1Pending,
+			wantErr:      true,
+			expectedErr:  datastore.ErrRecordNotFound,
 		},
 		{
 			name:        "Error - Table Not Found",
@@ -236,6 +260,7 @@ func TestAddCommand(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(t.Name(), func(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
+			preloadMockDB(t, db, bucketEntity, tt.entities)
 			store := DataStore{db: db}
 
 			err := store.AddCommand(tt.inputCommand)
@@ -263,6 +288,7 @@ func TestResolveCommand(t *testing.T) {
 
 		inputID     string
 		inputStatus types.CommandStatus
+		inputReason string
 		wantErr     bool
 		expectedErr error
 	}{
@@ -275,6 +301,16 @@ func TestResolveCommand(t *testing.T) {
 			inputID:     "cmd1",
 			inputStatus: types.CommandStatusSuccess,
 		},
+		{
+			name:   "Success - With Reason",
+			bucket: bucketCommand,
+			mocks: map[string]string{
+				"cmd1": _data.MockCommand1Pending,
+			},
+			inputID:     "cmd1",
+			inputStatus: types.CommandStatusFailure,
+			inputReason: "command timed out",
+		},
 		{
 			name:        "Error - Table Does Not Exist",
 			bucket:      "test",
@@ -298,7 +334,7 @@ func TestResolveCommand(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
 			store := DataStore{db: db}
 
-			err := store.ResolveCommand(tt.inputID, tt.inputStatus)
+			err := store.ResolveCommand(tt.inputID, tt.inputStatus, tt.inputReason)
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -399,4 +435,13 @@ var (
 		IssuedAt:     time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
 		ResolvedAt:   _data.Ptr(time.Date(2011, 11, 10, 23, 0, 10, 0, time.UTC)),
 	}
+
+	mockCommand2PendingTimeout = types.Command{
+		ID:           "cmd4",
+		EntityID:     "2",
+		DesiredState: map[string]any{"power": "on"},
+		Status:       types.CommandStatusPending,
+		IssuedAt:     time.Date(2012, 11, 10, 23, 0, 0, 0, time.UTC),
+		TimeoutAt:    _data.Ptr(time.Date(2012, 11, 10, 23, 0, 30, 0, time.UTC)),
+	}
 )
@@ -0,0 +1,110 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"go.etcd.io/bbolt"
+)
+
+func (s *DataStore) GetDeadLetterByID(id int) (types.DeadLetter, error) {
+	var deadLetter types.DeadLetter
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketDeadLetter))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		data := bucket.Get([]byte(strconv.Itoa(id)))
+		if data == nil {
+			return datastore.ErrRecordNotFound
+		}
+
+		if err := json.Unmarshal(data, &deadLetter); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return types.DeadLetter{}, err
+	}
+
+	return deadLetter, nil
+}
+
+func (s *DataStore) ListDeadLetters(options datastore.ListOptions) (datastore.Page[types.DeadLetter], error) {
+	var page datastore.Page[types.DeadLetter]
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketDeadLetter))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		var err error
+		page, err = listPage(bucket, datastore.DeadLetterOrdering, options, false, func(types.DeadLetter) bool {
+			return true
+		})
+		return err
+	})
+
+	if err != nil {
+		return datastore.Page[types.DeadLetter]{}, err
+	}
+
+	return page, nil
+}
+
+func (s *DataStore) AddDeadLetter(deadLetter types.DeadLetter) (int, error) {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketDeadLetter))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		id, _ := bucket.NextSequence()
+		deadLetter.ID = int(id)
+
+		data, err := json.Marshal(deadLetter)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if err := bucket.Put([]byte(strconv.Itoa(deadLetter.ID)), data); err != nil {
+			return datastore.ErrTransactionFailed
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return deadLetter.ID, nil
+}
+
+func (s *DataStore) DeleteDeadLetter(id int) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketDeadLetter))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		if bucket.Get([]byte(strconv.Itoa(id))) == nil {
+			return datastore.ErrRecordNotFound
+		}
+
+		if err := bucket.Delete([]byte(strconv.Itoa(id))); err != nil {
+			return datastore.ErrTransactionFailed
+		}
+
+		return nil
+	})
+}
@@ -2,6 +2,8 @@ package boltdb
 
 import (
 	"encoding/json"
+	"sort"
+	"strconv"
 
 	"github.com/pmoura-dev/esr-service/internal/datastore"
 	"github.com/pmoura-dev/esr-service/internal/types"
@@ -37,8 +39,8 @@ func (s *DataStore) GetEntityByID(id string) (types.Entity, error) {
 	return entity, nil
 }
 
-func (s *DataStore) ListEntities() ([]types.Entity, error) {
-	var entityList []types.Entity
+func (s *DataStore) ListEntities(options datastore.ListOptions) (datastore.Page[types.Entity], error) {
+	var page datastore.Page[types.Entity]
 
 	err := s.db.View(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketEntity))
@@ -46,23 +48,18 @@ func (s *DataStore) ListEntities() ([]types.Entity, error) {
 			return datastore.ErrTableDoesNotExist
 		}
 
-		return bucket.ForEach(func(_, data []byte) error {
-			var entity types.Entity
-
-			if err := json.Unmarshal(data, &entity); err != nil {
-				return datastore.ErrInvalidData
-			}
-
-			entityList = append(entityList, entity)
-			return nil
+		var err error
+		page, err = listPage(bucket, datastore.EntityOrdering, options, true, func(types.Entity) bool {
+			return true
 		})
+		return err
 	})
 
 	if err != nil {
-		return nil, err
+		return datastore.Page[types.Entity]{}, err
 	}
 
-	return entityList, nil
+	return page, nil
 }
 
 func (s *DataStore) AddEntity(entity types.Entity) error {
@@ -89,21 +86,92 @@ func (s *DataStore) AddEntity(entity types.Entity) error {
 	})
 }
 
-func (s *DataStore) DeleteEntity(id string) error {
+func (s *DataStore) DeleteEntity(id string, policy datastore.DeletionPolicy) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucketEntity))
-		if bucket == nil {
+		entityBucket := tx.Bucket([]byte(bucketEntity))
+		if entityBucket == nil {
 			return datastore.ErrTableDoesNotExist
 		}
 
-		if bucket.Get([]byte(id)) == nil {
+		if entityBucket.Get([]byte(id)) == nil {
 			return datastore.ErrRecordNotFound
 		}
 
-		if err := bucket.Delete([]byte(id)); err != nil {
+		commandBucket := tx.Bucket([]byte(bucketCommand))
+		reportSubscriptionBucket := tx.Bucket([]byte(bucketReportSubscription))
+		stateBucket := tx.Bucket([]byte(bucketState))
+		outboxBucket := tx.Bucket([]byte(bucketOutbox))
+		if commandBucket == nil || reportSubscriptionBucket == nil || stateBucket == nil || outboxBucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		commandKeys, err := keysByEntityID(commandBucket, id)
+		if err != nil {
+			return err
+		}
+
+		reportSubscriptionKeys, err := keysByEntityID(reportSubscriptionBucket, id)
+		if err != nil {
+			return err
+		}
+
+		if policy != datastore.DeletionPolicyCascade && (len(commandKeys) > 0 || len(reportSubscriptionKeys) > 0) {
+			var references types.EntityReferences
+			for _, key := range commandKeys {
+				references.Commands = append(references.Commands, string(key))
+			}
+			for _, key := range reportSubscriptionKeys {
+				reportSubscriptionID, err := strconv.Atoi(string(key))
+				if err != nil {
+					return datastore.ErrInvalidData
+				}
+				references.ReportSubscriptions = append(references.ReportSubscriptions, reportSubscriptionID)
+			}
+
+			sort.Ints(references.ReportSubscriptions)
+
+			return &datastore.ReferencedError{References: references}
+		}
+
+		outboxKeys, err := pendingOutboxKeysByEntityID(outboxBucket, id)
+		if err != nil {
+			return err
+		}
+
+		if err := deleteKeys(commandBucket, commandKeys); err != nil {
+			return err
+		}
+
+		if err := deleteKeys(reportSubscriptionBucket, reportSubscriptionKeys); err != nil {
+			return err
+		}
+
+		if err := deleteStates(stateBucket, id); err != nil {
+			return err
+		}
+
+		if err := deleteKeys(outboxBucket, outboxKeys); err != nil {
+			return err
+		}
+
+		if err := entityBucket.Delete([]byte(id)); err != nil {
 			return datastore.ErrTransactionFailed
 		}
 
 		return nil
 	})
 }
+
+// entityExists returns ErrRecordNotFound when the entity does not exist in tx.
+func entityExists(tx *bbolt.Tx, id string) error {
+	bucket := tx.Bucket([]byte(bucketEntity))
+	if bucket == nil {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if bucket.Get([]byte(id)) == nil {
+		return datastore.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -126,7 +126,7 @@ func TestGetAllEntities(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
 			store := DataStore{db: db}
 
-			got, err := store.ListEntities()
+			page, err := store.ListEntities(datastore.ListOptions{})
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -140,8 +140,8 @@ func TestGetAllEntities(t *testing.T) {
 				return
 			}
 
-			if !reflect.DeepEqual(tt.expected, got) {
-				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			if !reflect.DeepEqual(tt.expected, page.Items) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, page.Items)
 			}
 		})
 	}
@@ -212,6 +212,8 @@ func TestDeleteEntity(t *testing.T) {
 		name   string
 		bucket string
 		mocks  map[string]string
+		// migrate creates the remaining buckets a delete cascades to
+		migrate bool
 
 		inputID     string
 		wantErr     bool
@@ -223,6 +225,7 @@ func TestDeleteEntity(t *testing.T) {
 			mocks: map[string]string{
 				"1": _data.MockEntity1,
 			},
+			migrate: true,
 			inputID: "1",
 		},
 		{
@@ -248,7 +251,13 @@ func TestDeleteEntity(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
 			store := DataStore{db: db}
 
-			err := store.DeleteEntity(tt.inputID)
+			if tt.migrate {
+				if err := store.Init(); err != nil {
+					t.Fatalf("failed to migrate mock db: %v", err)
+				}
+			}
+
+			err := store.DeleteEntity(tt.inputID, datastore.DeletionPolicyRestrict)
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -0,0 +1,253 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"go.etcd.io/bbolt"
+)
+
+// outboxKey encodes a message ID in big endian, so messages are iterated in
+// the order they were added.
+func outboxKey(id int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+	return key
+}
+
+// putOutboxMessages adds messages to the outbox within an existing transaction.
+func putOutboxMessages(tx *bbolt.Tx, messages []types.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	bucket := tx.Bucket([]byte(bucketOutbox))
+	if bucket == nil {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	for _, message := range messages {
+		id, _ := bucket.NextSequence()
+		message.ID = int(id)
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if err := bucket.Put(outboxKey(message.ID), data); err != nil {
+			return datastore.ErrTransactionFailed
+		}
+	}
+
+	return nil
+}
+
+func (s *DataStore) AddOutboxMessages(messages ...types.OutboxMessage) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putOutboxMessages(tx, messages)
+	})
+}
+
+func (s *DataStore) ListPendingOutboxMessages(limit int) ([]types.OutboxMessage, error) {
+	var messageList []types.OutboxMessage
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketOutbox))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		c := bucket.Cursor()
+		for key, data := c.First(); key != nil && (limit <= 0 || len(messageList) < limit); key, data = c.Next() {
+			var message types.OutboxMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if message.SentAt == nil {
+				messageList = append(messageList, message)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return messageList, nil
+}
+
+func (s *DataStore) ClaimPendingOutboxMessages(limit int, now time.Time, lease time.Duration) ([]types.OutboxMessage, error) {
+	var messageList []types.OutboxMessage
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketOutbox))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		claimedUntil := now.Add(lease)
+
+		c := bucket.Cursor()
+		for key, data := c.First(); key != nil && (limit <= 0 || len(messageList) < limit); key, data = c.Next() {
+			var message types.OutboxMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if message.SentAt == nil && (message.ClaimedUntil == nil || message.ClaimedUntil.Before(now)) {
+				message.ClaimedUntil = &claimedUntil
+				messageList = append(messageList, message)
+			}
+		}
+
+		// the bucket is not modified while the cursor iterates it
+		for _, message := range messageList {
+			data, err := json.Marshal(message)
+			if err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if err := bucket.Put(outboxKey(message.ID), data); err != nil {
+				return datastore.ErrTransactionFailed
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return messageList, nil
+}
+
+func (s *DataStore) ReleaseOutboxMessages(ids []int) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketOutbox))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		for _, id := range ids {
+			data := bucket.Get(outboxKey(id))
+			if data == nil {
+				continue
+			}
+
+			var message types.OutboxMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if message.SentAt != nil {
+				continue
+			}
+
+			message.ClaimedUntil = nil
+
+			data, err := json.Marshal(message)
+			if err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if err := bucket.Put(outboxKey(id), data); err != nil {
+				return datastore.ErrTransactionFailed
+			}
+		}
+
+		return nil
+	})
+}
+
+// pendingOutboxKeysByEntityID returns the keys of the unsent messages of an entity.
+func pendingOutboxKeysByEntityID(bucket *bbolt.Bucket, entityID string) ([][]byte, error) {
+	var keys [][]byte
+
+	err := bucket.ForEach(func(key, data []byte) error {
+		var message types.OutboxMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if message.EntityID == entityID && message.SentAt == nil {
+			keys = append(keys, append([]byte{}, key...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *DataStore) MarkOutboxMessageSent(id int, sentAt time.Time) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketOutbox))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		data := bucket.Get(outboxKey(id))
+		if data == nil {
+			return datastore.ErrRecordNotFound
+		}
+
+		var message types.OutboxMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		message.SentAt = &sentAt
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if err := bucket.Put(outboxKey(id), data); err != nil {
+			return datastore.ErrTransactionFailed
+		}
+
+		return nil
+	})
+}
+
+func (s *DataStore) DeleteSentOutboxMessages(sentBefore time.Time) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketOutbox))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		var keys [][]byte
+
+		err := bucket.ForEach(func(key, data []byte) error {
+			var message types.OutboxMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if message.SentAt != nil && message.SentAt.Before(sentBefore) {
+				keys = append(keys, append([]byte{}, key...))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteKeys(bucket, keys)
+	})
+}
@@ -2,7 +2,9 @@ package boltdb
 
 import (
 	"encoding/json"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/pmoura-dev/esr-service/internal/datastore"
 	"github.com/pmoura-dev/esr-service/internal/types"
@@ -39,8 +41,8 @@ func (s *DataStore) GetReportSubscriptionByID(id int) (types.ReportSubscription,
 	return subscription, nil
 }
 
-func (s *DataStore) ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription]) ([]types.ReportSubscription, error) {
-	var subscriptionList []types.ReportSubscription
+func (s *DataStore) ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription], options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error) {
+	var page datastore.Page[types.ReportSubscription]
 
 	err := s.db.View(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketReportSubscription))
@@ -48,35 +50,31 @@ func (s *DataStore) ListReportSubscriptions(filter datastore.Filter[types.Report
 			return datastore.ErrTableDoesNotExist
 		}
 
-		return bucket.ForEach(func(_, data []byte) error {
-			var subscription types.ReportSubscription
-
-			if err := json.Unmarshal(data, &subscription); err != nil {
-				return datastore.ErrInvalidData
-			}
-
-			if filter.Check(subscription) {
-				subscriptionList = append(subscriptionList, subscription)
-			}
-
-			return nil
+		var err error
+		page, err = listPage(bucket, datastore.ReportSubscriptionOrdering, options, false, func(subscription types.ReportSubscription) bool {
+			return filter.Check(subscription)
 		})
+		return err
 	})
 
 	if err != nil {
-		return nil, err
+		return datastore.Page[types.ReportSubscription]{}, err
 	}
 
-	return subscriptionList, nil
+	return page, nil
 }
 
-func (s *DataStore) AddReportSubscription(reportSubscription types.ReportSubscription) error {
-	return s.db.Update(func(tx *bbolt.Tx) error {
+func (s *DataStore) AddReportSubscription(reportSubscription types.ReportSubscription, announce ...datastore.ReportingAnnouncer) (int, error) {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketReportSubscription))
 		if bucket == nil {
 			return datastore.ErrTableDoesNotExist
 		}
 
+		if err := entityExists(tx, reportSubscription.EntityID); err != nil {
+			return err
+		}
+
 		id, _ := bucket.NextSequence()
 		reportSubscription.ID = int(id)
 
@@ -89,30 +87,51 @@ func (s *DataStore) AddReportSubscription(reportSubscription types.ReportSubscri
 			return datastore.ErrTransactionFailed
 		}
 
-		return nil
+		return announceReporting(tx, bucket, reportSubscription.EntityID, announce)
 	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return reportSubscription.ID, nil
 }
 
-func (s *DataStore) DeleteReportSubscription(id int) error {
+func (s *DataStore) DeleteReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketReportSubscription))
 		if bucket == nil {
 			return datastore.ErrTableDoesNotExist
 		}
 
-		if bucket.Get([]byte(strconv.Itoa(id))) == nil {
+		data := bucket.Get([]byte(strconv.Itoa(id)))
+		if data == nil {
 			return datastore.ErrRecordNotFound
 		}
 
+		var subscription types.ReportSubscription
+
+		if err := json.Unmarshal(data, &subscription); err != nil {
+			return datastore.ErrInvalidData
+		}
+
 		if err := bucket.Delete([]byte(strconv.Itoa(id))); err != nil {
 			return datastore.ErrTransactionFailed
 		}
 
-		return nil
+		return announceReporting(tx, bucket, subscription.EntityID, announce)
 	})
 }
 
-func (s *DataStore) ActivateReportSubscription(id int) error {
+func (s *DataStore) ActivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, true, announce)
+}
+
+func (s *DataStore) DeactivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, false, announce)
+}
+
+func (s *DataStore) setReportSubscriptionActive(id int, isActive bool, announce []datastore.ReportingAnnouncer) error {
 	return s.db.Update(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(bucketReportSubscription))
 		if bucket == nil {
@@ -130,7 +149,8 @@ func (s *DataStore) ActivateReportSubscription(id int) error {
 			return datastore.ErrInvalidData
 		}
 
-		subscription.IsActive = true
+		subscription.IsActive = isActive
+		subscription.UpdatedAt = time.Now()
 		data, err := json.Marshal(subscription)
 		if err != nil {
 			return datastore.ErrInvalidData
@@ -140,38 +160,48 @@ func (s *DataStore) ActivateReportSubscription(id int) error {
 			return datastore.ErrTransactionFailed
 		}
 
-		return nil
+		return announceReporting(tx, bucket, subscription.EntityID, announce)
 	})
 }
 
-func (s *DataStore) DeactivateReportSubscription(id int) error {
-	return s.db.Update(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucketReportSubscription))
-		if bucket == nil {
-			return datastore.ErrTableDoesNotExist
-		}
+// announceReporting stores the outbox messages announcing the active
+// subscriptions of entityID, as they are in tx.
+func announceReporting(tx *bbolt.Tx, bucket *bbolt.Bucket, entityID string, announce []datastore.ReportingAnnouncer) error {
+	if len(announce) == 0 {
+		return nil
+	}
 
-		data := bucket.Get([]byte(strconv.Itoa(id)))
-		if data == nil {
-			return datastore.ErrRecordNotFound
-		}
+	active := []types.ReportSubscription{}
 
+	err := bucket.ForEach(func(_, data []byte) error {
 		var subscription types.ReportSubscription
 
 		if err := json.Unmarshal(data, &subscription); err != nil {
 			return datastore.ErrInvalidData
 		}
 
-		subscription.IsActive = false
-		data, err := json.Marshal(subscription)
+		if subscription.EntityID == entityID && subscription.IsActive {
+			active = append(active, subscription)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+
+	for _, announcer := range announce {
+		messages, err := announcer(entityID, active)
 		if err != nil {
-			return datastore.ErrInvalidData
+			return err
 		}
 
-		if err := bucket.Put([]byte(strconv.Itoa(id)), data); err != nil {
-			return datastore.ErrTransactionFailed
+		if err := putOutboxMessages(tx, messages); err != nil {
+			return err
 		}
+	}
 
-		return nil
-	})
+	return nil
 }
@@ -204,7 +204,7 @@ func TestListReportSubscriptions(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
 			store := DataStore{db: db}
 
-			got, err := store.ListReportSubscriptions(tt.inputFilter)
+			page, err := store.ListReportSubscriptions(tt.inputFilter, datastore.ListOptions{})
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -218,8 +218,8 @@ func TestListReportSubscriptions(t *testing.T) {
 				return
 			}
 
-			if !reflect.DeepEqual(tt.expected, got) {
-				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			if !reflect.DeepEqual(tt.expected, page.Items) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, page.Items)
 			}
 		})
 	}
@@ -227,18 +227,29 @@ func TestListReportSubscriptions(t *testing.T) {
 
 func TestAddReportSubscription(t *testing.T) {
 	tests := []struct {
-		name   string
-		bucket string
-		mocks  map[string]string
+		name     string
+		bucket   string
+		mocks    map[string]string
+		entities map[string]string
 
 		inputReportSubscription types.ReportSubscription
+		expectedID              int
 		wantErr                 bool
 		expectedErr             error
 	}{
 		{
 			name:                    "Success",
 			bucket:                  bucketReportSubscription,
+			entities:                map[string]string{"entity_1": `{"id": "entity_1", "name": "TestEntity1"}`},
 			inputReportSubscription: mockReportSubscription1State,
+			expectedID:              1,
+		},
+		{
+			name:                    "Error - Entity Not Found",
+			bucket:                  bucketReportSubscription,
+			inputReportSubscription: mockReportSubscription1State,
+			wantErr:                 true,
+			expectedErr:             datastore.ErrRecordNotFound,
 		},
 		{
 			name:        "Error - Table Not Found",
@@ -251,9 +262,10 @@ func TestAddReportSubscription(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(t.Name(), func(t *testing.T) {
 			db := setupMockDB(t, tt.bucket, tt.mocks)
+			preloadMockDB(t, db, bucketEntity, tt.entities)
 			store := DataStore{db: db}
 
-			err := store.AddReportSubscription(tt.inputReportSubscription)
+			got, err := store.AddReportSubscription(tt.inputReportSubscription)
 
 			if tt.wantErr {
 				if !errors.Is(err, tt.expectedErr) {
@@ -266,6 +278,10 @@ func TestAddReportSubscription(t *testing.T) {
 				t.Errorf("Test failed. Unexpected error: %v", err)
 				return
 			}
+
+			if tt.expectedID != got {
+				t.Errorf("Test failed. Expected ID: %v, Got: %v", tt.expectedID, got)
+			}
 		})
 	}
 }
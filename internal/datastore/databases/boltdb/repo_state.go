@@ -0,0 +1,248 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"go.etcd.io/bbolt"
+)
+
+// States are kept in a nested bucket per entity, keyed by reporting time and
+// then ID, so the states of an entity are read without scanning the others,
+// and its latest state is the last key of its bucket. The ID sequence is the
+// one of the state bucket.
+
+// stateTimeKey encodes t so that keys sort in time order: the seconds, with
+// the sign bit flipped, then the nanoseconds, both big-endian.
+func stateTimeKey(t time.Time) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key, uint64(t.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(key[8:], uint32(t.Nanosecond()))
+
+	return key
+}
+
+func stateKey(state types.State) []byte {
+	key := make([]byte, 20)
+	copy(key, stateTimeKey(state.ReportedAt))
+	binary.BigEndian.PutUint64(key[12:], uint64(state.ID))
+
+	return key
+}
+
+func putState(bucket *bbolt.Bucket, state types.State) error {
+	entityBucket, err := bucket.CreateBucketIfNotExists([]byte(state.EntityID))
+	if err != nil {
+		return datastore.ErrTransactionFailed
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	if err := entityBucket.Put(stateKey(state), data); err != nil {
+		return datastore.ErrTransactionFailed
+	}
+
+	return nil
+}
+
+// deleteStates deletes the states of entityID, if any.
+func deleteStates(bucket *bbolt.Bucket, entityID string) error {
+	err := bucket.DeleteBucket([]byte(entityID))
+	if err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+		return datastore.ErrTransactionFailed
+	}
+
+	return nil
+}
+
+// stateRange returns the entity and the earliest reporting time the filter
+// restricts states to, when it says so with its conditions.
+func stateRange(filter datastore.Filter[types.State]) (entityID *string, reportedAfter *time.Time) {
+	conditioner, ok := filter.(filters.Conditioner)
+	if !ok {
+		return nil, nil
+	}
+
+	for _, condition := range conditioner.Conditions() {
+		switch {
+		case condition.Field == filters.FieldEntityID && condition.Operator == filters.OperatorEqual:
+			if value, ok := condition.Value.(string); ok {
+				entityID = &value
+			}
+		case condition.Field == filters.FieldReportedAt && condition.Operator == filters.OperatorGreaterThan:
+			if value, ok := condition.Value.(time.Time); ok {
+				reportedAfter = &value
+			}
+		}
+	}
+
+	return entityID, reportedAfter
+}
+
+func (s *DataStore) GetLatestStateByEntityID(entityID string) (types.State, error) {
+	var state types.State
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketState))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		entityBucket := bucket.Bucket([]byte(entityID))
+		if entityBucket == nil {
+			return datastore.ErrRecordNotFound
+		}
+
+		key, data := entityBucket.Cursor().Last()
+		if key == nil {
+			return datastore.ErrRecordNotFound
+		}
+
+		if err := json.Unmarshal(data, &state); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return types.State{}, err
+	}
+
+	return state, nil
+}
+
+func (s *DataStore) ListStates(filter datastore.Filter[types.State]) ([]types.State, error) {
+	var stateList []types.State
+
+	entityID, reportedAfter := stateRange(filter)
+
+	// listEntity appends the matching states of an entity bucket, starting
+	// from the earliest reporting time the filter allows
+	listEntity := func(entityBucket *bbolt.Bucket) error {
+		cursor := entityBucket.Cursor()
+
+		key, data := cursor.First()
+		if reportedAfter != nil {
+			key, data = cursor.Seek(stateTimeKey(*reportedAfter))
+		}
+
+		for ; key != nil; key, data = cursor.Next() {
+			var state types.State
+
+			if err := json.Unmarshal(data, &state); err != nil {
+				return datastore.ErrInvalidData
+			}
+
+			if filter.Check(state) {
+				stateList = append(stateList, state)
+			}
+		}
+
+		return nil
+	}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketState))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		if entityID != nil {
+			entityBucket := bucket.Bucket([]byte(*entityID))
+			if entityBucket == nil {
+				return nil
+			}
+
+			return listEntity(entityBucket)
+		}
+
+		return bucket.ForEachBucket(func(key []byte) error {
+			return listEntity(bucket.Bucket(key))
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stateList, nil
+}
+
+func (s *DataStore) AddState(state types.State) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketState))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		id, _ := bucket.NextSequence()
+		state.ID = int(id)
+
+		return putState(bucket, state)
+	})
+}
+
+func (s *DataStore) DeleteStatesByEntityID(entityID string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketState))
+		if bucket == nil {
+			return datastore.ErrTableDoesNotExist
+		}
+
+		return deleteStates(bucket, entityID)
+	})
+}
+
+// keyStatesByEntity moves the states stored flat in the state bucket, keyed
+// by ID, to the bucket of their entity.
+func keyStatesByEntity(tx *bbolt.Tx) error {
+	bucket := tx.Bucket([]byte(bucketState))
+	if bucket == nil {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	var keys [][]byte
+	var states []types.State
+
+	err := bucket.ForEach(func(key, data []byte) error {
+		// nested buckets have no value
+		if data == nil {
+			return nil
+		}
+
+		var state types.State
+		if err := json.Unmarshal(data, &state); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		keys = append(keys, append([]byte{}, key...))
+		states = append(states, state)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := deleteKeys(bucket, keys); err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		if err := putState(bucket, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/_data"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"go.etcd.io/bbolt"
+)
+
+func TestGetLatestStateByEntityID(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		mocks  []string
+
+		inputEntityID string
+		expected      types.State
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name:   "Success",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				_data.MockState2On,
+			},
+			inputEntityID: "1",
+			expected:      mockState1On,
+		},
+		{
+			name:   "Success - Reported Out Of Order",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				`{"id": 4, "entity_id": "1", "state": {"power": "off"}, "reported_at": "2009-12-10T23:00:00Z"}`,
+			},
+			inputEntityID: "1",
+			expected:      mockState1On,
+		},
+		{
+			name:        "Error - Table Not Found",
+			bucket:      "test",
+			wantErr:     true,
+			expectedErr: datastore.ErrTableDoesNotExist,
+		},
+		{
+			name:   "Error - Invalid Data",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockStateInvalid,
+			},
+			inputEntityID: "1",
+			wantErr:       true,
+			expectedErr:   datastore.ErrInvalidData,
+		},
+		{
+			name:   "Error - Record Not Found",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState2On,
+			},
+			inputEntityID: "1",
+			wantErr:       true,
+			expectedErr:   datastore.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupMockStateDB(t, tt.bucket, tt.mocks)
+			store := DataStore{db: db}
+
+			got, err := store.GetLatestStateByEntityID(tt.inputEntityID)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestListStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		mocks  []string
+
+		inputFilter datastore.Filter[types.State]
+		expected    []types.State
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:   "Success - No filter",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				_data.MockState2On,
+			},
+			inputFilter: filters.NewStateFilter(),
+			expected: []types.State{
+				mockState1Off,
+				mockState1On,
+				mockState2On,
+			},
+		},
+		{
+			name:   "Success - Filter by: EntityID",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				_data.MockState2On,
+			},
+			inputFilter: filters.NewStateFilter().ByEntityID("1"),
+			expected: []types.State{
+				mockState1Off,
+				mockState1On,
+			},
+		},
+		{
+			name:   "Success - Filter by: Time Range",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				_data.MockState2On,
+			},
+			inputFilter: filters.NewStateFilter().
+				ByTimeAfterReporting(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)).
+				ByTimeBeforeReporting(time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)),
+			expected: []types.State{
+				mockState1On,
+			},
+		},
+		{
+			name:        "Error - Table Not Found",
+			bucket:      "test",
+			wantErr:     true,
+			expectedErr: datastore.ErrTableDoesNotExist,
+		},
+		{
+			name:   "Error - Invalid Data",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockStateInvalid,
+			},
+			wantErr:     true,
+			expectedErr: datastore.ErrInvalidData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupMockStateDB(t, tt.bucket, tt.mocks)
+			store := DataStore{db: db}
+
+			got, err := store.ListStates(tt.inputFilter)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAddState(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		mocks  []string
+
+		inputState  types.State
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:       "Success",
+			bucket:     bucketState,
+			inputState: mockState1Off,
+		},
+		{
+			name:        "Error - Table Not Found",
+			bucket:      "test",
+			wantErr:     true,
+			expectedErr: datastore.ErrTableDoesNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupMockStateDB(t, tt.bucket, tt.mocks)
+			store := DataStore{db: db}
+
+			err := store.AddState(tt.inputState)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+		})
+	}
+}
+
+func TestDeleteStatesByEntityID(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket string
+		mocks  []string
+
+		inputEntityID string
+		expected      []types.State
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name:   "Success",
+			bucket: bucketState,
+			mocks: []string{
+				_data.MockState1Off,
+				_data.MockState1On,
+				_data.MockState2On,
+			},
+			inputEntityID: "1",
+			expected: []types.State{
+				mockState2On,
+			},
+		},
+		{
+			name:        "Error - Table Does Not Exist",
+			bucket:      "test",
+			wantErr:     true,
+			expectedErr: datastore.ErrTableDoesNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupMockStateDB(t, tt.bucket, tt.mocks)
+			store := DataStore{db: db}
+
+			err := store.DeleteStatesByEntityID(tt.inputEntityID)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			got, err := store.ListStates(filters.NewStateFilter())
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestKeyStatesByEntity(t *testing.T) {
+	db := setupMockDB(t, bucketState, map[string]string{
+		"1": _data.MockState1Off,
+		"2": _data.MockState1On,
+		"3": _data.MockState2On,
+	})
+	store := DataStore{db: db}
+
+	if err := db.Update(keyStatesByEntity); err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	got, err := store.ListStates(filters.NewStateFilter())
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	expected := []types.State{mockState1Off, mockState1On, mockState2On}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+	}
+
+	latest, err := store.GetLatestStateByEntityID("1")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(mockState1On, latest) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", mockState1On, latest)
+	}
+}
+
+// setupMockStateDB stores the states in the buckets of their entity. Data
+// that is not a state is stored in the bucket of entity "1".
+func setupMockStateDB(t *testing.T, bucket string, mocks []string) *bbolt.DB {
+	db := setupMockDB(t, bucket, nil)
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucket))
+
+		for _, mock := range mocks {
+			var state types.State
+			if err := json.Unmarshal([]byte(mock), &state); err != nil {
+				entityBucket, err := bucket.CreateBucketIfNotExists([]byte("1"))
+				if err != nil {
+					return err
+				}
+
+				if err := entityBucket.Put([]byte("invalid"), []byte(mock)); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := putState(bucket, state); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("failed to preload mock db: %v", err)
+	}
+
+	return db
+}
+
+var (
+	mockState1Off = types.State{
+		ID:         1,
+		EntityID:   "1",
+		State:      map[string]any{"power": "off"},
+		ReportedAt: time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockState1On = types.State{
+		ID:         2,
+		EntityID:   "1",
+		State:      map[string]any{"power": "on"},
+		ReportedAt: time.Date(2010, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockState2On = types.State{
+		ID:         3,
+		EntityID:   "2",
+		State:      map[string]any{"power": "on"},
+		ReportedAt: time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+)
@@ -6,12 +6,21 @@ import (
 	"github.com/pmoura-dev/esr-service/internal/config"
 	"github.com/pmoura-dev/esr-service/internal/datastore"
 	"github.com/pmoura-dev/esr-service/internal/datastore/databases/boltdb"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/memory"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/postgres"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/sqlite"
 )
 
 func GetDataStore(config config.DataStoreConfig) (datastore.DataStore, error) {
 	switch config.DataStoreType {
 	case boltdb.Name:
 		return boltdb.NewBoltDBDataStore(config)
+	case memory.Name:
+		return memory.NewMemoryDataStore(config)
+	case sqlite.Name:
+		return sqlite.NewSQLiteDataStore(config)
+	case postgres.Name:
+		return postgres.NewPostgresDataStore(config)
 	default:
 		return nil, fmt.Errorf("unknown datastore type: %s", config.DataStoreType)
 	}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/datastoretest"
+)
+
+func TestConformance(t *testing.T) {
+	datastoretest.RunConformanceTests(t, func() datastore.DataStore {
+		store, err := NewMemoryDataStore(config.DataStoreConfig{})
+		if err != nil {
+			t.Errorf("failed to create memory datastore: %v", err)
+			return nil
+		}
+
+		return store
+	})
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+const (
+	tableEntity             = "Entity"
+	tableCommand            = "Command"
+	tableReportSubscription = "ReportSubscription"
+	tableState              = "State"
+	tableOutbox             = "Outbox"
+	tableDeadLetter         = "DeadLetter"
+)
+
+func (s *DataStore) Init() error {
+	if err := datastore.ValidateMigrations(migrations); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, migration := range datastore.PendingMigrations(migrations, s.version) {
+		if err := migration.Apply(s); err != nil {
+			return err
+		}
+
+		s.version = migration.Version
+	}
+
+	return nil
+}
+
+func (s *DataStore) SchemaVersion() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version, nil
+}
+
+func (s *DataStore) MigrationStatus() ([]datastore.MigrationStatus, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return datastore.MigrationStatusList(migrations, current), nil
+}
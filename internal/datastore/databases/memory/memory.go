@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+const (
+	Name = "memory"
+)
+
+// DataStore represents an in-memory datastore.
+//
+// Records are kept JSON encoded, just like in BoltDB, so that callers never
+// share memory with the store and both backends return identical values.
+type DataStore struct {
+	mu sync.RWMutex
+
+	tables    map[string]map[string][]byte
+	sequences map[string]int
+	version   int
+}
+
+func NewMemoryDataStore(_ config.DataStoreConfig) (*DataStore, error) {
+	return &DataStore{
+		tables:    make(map[string]map[string][]byte),
+		sequences: make(map[string]int),
+	}, nil
+}
+
+func (s *DataStore) Close() {}
+
+// sortedKeys returns the keys of a table in the same order BoltDB iterates them.
+func sortedKeys(table map[string][]byte) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *DataStore) nextSequence(table string) int {
+	s.sequences[table]++
+	return s.sequences[table]
+}
+
+// keysByEntityID returns the sorted keys of every record in table that belongs to entityID.
+func keysByEntityID(table map[string][]byte, entityID string) ([]string, error) {
+	var keys []string
+
+	for _, key := range sortedKeys(table) {
+		var record struct {
+			EntityID string `json:"entity_id"`
+		}
+
+		if err := json.Unmarshal(table[key], &record); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if record.EntityID == entityID {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func deleteKeys(table map[string][]byte, keys []string) {
+	for _, key := range keys {
+		delete(table, key)
+	}
+}
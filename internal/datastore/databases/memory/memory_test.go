@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func setupMockStore(t *testing.T) *DataStore {
+	store, err := NewMemoryDataStore(config.DataStoreConfig{})
+	if err != nil {
+		t.Fatalf("failed to create memory datastore: %v", err)
+	}
+
+	if err := store.Init(); err != nil {
+		t.Fatalf("failed to init memory datastore: %v", err)
+	}
+
+	return store
+}
+
+func TestTableDoesNotExistBeforeInit(t *testing.T) {
+	store, err := NewMemoryDataStore(config.DataStoreConfig{})
+	if err != nil {
+		t.Fatalf("failed to create memory datastore: %v", err)
+	}
+
+	if _, err := store.GetEntityByID("1"); !errors.Is(err, datastore.ErrTableDoesNotExist) {
+		t.Errorf("Test failed. Expected error: %v, Got: %v", datastore.ErrTableDoesNotExist, err)
+	}
+}
+
+func TestStoredValuesAreCopies(t *testing.T) {
+	store := setupMockStore(t)
+
+	command := types.Command{
+		ID:           "cmd1",
+		EntityID:     "1",
+		DesiredState: map[string]any{"power": "on"},
+		Status:       types.CommandStatusPending,
+		IssuedAt:     time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	if err := store.AddEntity(types.Entity{ID: "1", Name: "lamp"}); err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if err := store.AddCommand(command); err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	command.DesiredState["power"] = "off"
+
+	got, err := store.GetCommandByID("cmd1")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(map[string]any{"power": "on"}, got.DesiredState) {
+		t.Errorf("Test failed. Stored command was mutated: %+v", got)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	store := setupMockStore(t)
+
+	for i := 0; i < 50; i++ {
+		if err := store.AddEntity(types.Entity{ID: fmt.Sprintf("entity_%d", i)}); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			_, _ = store.AddReportSubscription(types.ReportSubscription{
+				EntityID:   fmt.Sprintf("entity_%d", i),
+				ReportType: types.ReportTypeState,
+			})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			_, _ = store.ListReportSubscriptions(filters.NewReportSubscriptionFilter(), datastore.ListOptions{})
+		}()
+	}
+	wg.Wait()
+
+	page, err := store.ListReportSubscriptions(filters.NewReportSubscriptionFilter(), datastore.ListOptions{})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if len(page.Items) != 50 {
+		t.Errorf("Test failed. Expected: %d subscriptions, Got: %d", 50, len(page.Items))
+	}
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+// migrations must only ever be appended to, applied migrations are never run again.
+var migrations = []datastore.Migration[*DataStore]{
+	{
+		Version:     1,
+		Description: "create entity, command, report subscription and state tables",
+		Apply:       createTables(tableEntity, tableCommand, tableReportSubscription, tableState),
+	},
+	{
+		Version:     2,
+		Description: "create outbox table",
+		Apply:       createTables(tableOutbox),
+	},
+	{
+		Version:     3,
+		Description: "create dead letter table",
+		Apply:       createTables(tableDeadLetter),
+	},
+}
+
+// createTables must be called with the datastore lock held.
+func createTables(names ...string) func(s *DataStore) error {
+	return func(s *DataStore) error {
+		for _, name := range names {
+			if _, ok := s.tables[name]; !ok {
+				s.tables[name] = make(map[string][]byte)
+			}
+		}
+
+		return nil
+	}
+}
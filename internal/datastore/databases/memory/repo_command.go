@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/_data"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func (s *DataStore) GetCommandByID(id string) (types.Command, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableCommand]
+	if !ok {
+		return types.Command{}, datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[id]
+	if !ok {
+		return types.Command{}, datastore.ErrRecordNotFound
+	}
+
+	var command types.Command
+	if err := json.Unmarshal(data, &command); err != nil {
+		return types.Command{}, datastore.ErrInvalidData
+	}
+
+	return command, nil
+}
+
+func (s *DataStore) ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableCommand]
+	if !ok {
+		return datastore.Page[types.Command]{}, datastore.ErrTableDoesNotExist
+	}
+
+	var commandList []types.Command
+	for _, key := range sortedKeys(table) {
+		var command types.Command
+
+		if err := json.Unmarshal(table[key], &command); err != nil {
+			return datastore.Page[types.Command]{}, datastore.ErrInvalidData
+		}
+
+		if filter.Check(command) {
+			commandList = append(commandList, command)
+		}
+	}
+
+	return datastore.CommandOrdering.Paginate(commandList, options)
+}
+
+func (s *DataStore) AddCommand(command types.Command, outbox ...types.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableCommand]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if err := s.entityExists(command.EntityID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(command)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	// the outbox is written first, so nothing is stored if it fails
+	if err := s.putOutboxMessages(outbox); err != nil {
+		return err
+	}
+
+	table[command.ID] = data
+	return nil
+}
+
+func (s *DataStore) ResolveCommand(id string, status types.CommandStatus, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableCommand]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[id]
+	if !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	var command types.Command
+	if err := json.Unmarshal(data, &command); err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	if command.Status != types.CommandStatusPending {
+		return datastore.ErrAlreadyResolved
+	}
+
+	command.Status = status
+	command.ResolvedAt = _data.Ptr(time.Now())
+	command.Reason = reason
+
+	data, err := json.Marshal(command)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	table[id] = data
+	return nil
+}
+
+func (s *DataStore) DeleteCommand(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableCommand]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if _, ok := table[id]; !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	delete(table, id)
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func (s *DataStore) GetDeadLetterByID(id int) (types.DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableDeadLetter]
+	if !ok {
+		return types.DeadLetter{}, datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[strconv.Itoa(id)]
+	if !ok {
+		return types.DeadLetter{}, datastore.ErrRecordNotFound
+	}
+
+	var deadLetter types.DeadLetter
+	if err := json.Unmarshal(data, &deadLetter); err != nil {
+		return types.DeadLetter{}, datastore.ErrInvalidData
+	}
+
+	return deadLetter, nil
+}
+
+func (s *DataStore) ListDeadLetters(options datastore.ListOptions) (datastore.Page[types.DeadLetter], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableDeadLetter]
+	if !ok {
+		return datastore.Page[types.DeadLetter]{}, datastore.ErrTableDoesNotExist
+	}
+
+	var deadLetterList []types.DeadLetter
+	for _, key := range sortedKeys(table) {
+		var deadLetter types.DeadLetter
+
+		if err := json.Unmarshal(table[key], &deadLetter); err != nil {
+			return datastore.Page[types.DeadLetter]{}, datastore.ErrInvalidData
+		}
+
+		deadLetterList = append(deadLetterList, deadLetter)
+	}
+
+	return datastore.DeadLetterOrdering.Paginate(deadLetterList, options)
+}
+
+func (s *DataStore) AddDeadLetter(deadLetter types.DeadLetter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableDeadLetter]
+	if !ok {
+		return 0, datastore.ErrTableDoesNotExist
+	}
+
+	deadLetter.ID = s.nextSequence(tableDeadLetter)
+
+	data, err := json.Marshal(deadLetter)
+	if err != nil {
+		return 0, datastore.ErrInvalidData
+	}
+
+	table[strconv.Itoa(deadLetter.ID)] = data
+	return deadLetter.ID, nil
+}
+
+func (s *DataStore) DeleteDeadLetter(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableDeadLetter]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if _, ok := table[strconv.Itoa(id)]; !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	delete(table, strconv.Itoa(id))
+	return nil
+}
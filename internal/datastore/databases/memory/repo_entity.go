@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func (s *DataStore) GetEntityByID(id string) (types.Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableEntity]
+	if !ok {
+		return types.Entity{}, datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[id]
+	if !ok {
+		return types.Entity{}, datastore.ErrRecordNotFound
+	}
+
+	var entity types.Entity
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return types.Entity{}, datastore.ErrInvalidData
+	}
+
+	return entity, nil
+}
+
+func (s *DataStore) ListEntities(options datastore.ListOptions) (datastore.Page[types.Entity], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableEntity]
+	if !ok {
+		return datastore.Page[types.Entity]{}, datastore.ErrTableDoesNotExist
+	}
+
+	var entityList []types.Entity
+	for _, key := range sortedKeys(table) {
+		var entity types.Entity
+
+		if err := json.Unmarshal(table[key], &entity); err != nil {
+			return datastore.Page[types.Entity]{}, datastore.ErrInvalidData
+		}
+
+		entityList = append(entityList, entity)
+	}
+
+	return datastore.EntityOrdering.Paginate(entityList, options)
+}
+
+func (s *DataStore) AddEntity(entity types.Entity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableEntity]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if _, ok := table[entity.ID]; ok {
+		return datastore.ErrDuplicateRecord
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return datastore.ErrTransactionFailed
+	}
+
+	table[entity.ID] = data
+	return nil
+}
+
+func (s *DataStore) DeleteEntity(id string, policy datastore.DeletionPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entityTable, ok := s.tables[tableEntity]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if _, ok := entityTable[id]; !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	commandTable, ok := s.tables[tableCommand]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+	reportSubscriptionTable, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+	stateTable, ok := s.tables[tableState]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+	outboxTable, ok := s.tables[tableOutbox]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	commandKeys, err := keysByEntityID(commandTable, id)
+	if err != nil {
+		return err
+	}
+
+	reportSubscriptionKeys, err := keysByEntityID(reportSubscriptionTable, id)
+	if err != nil {
+		return err
+	}
+
+	if policy != datastore.DeletionPolicyCascade && (len(commandKeys) > 0 || len(reportSubscriptionKeys) > 0) {
+		references := types.EntityReferences{Commands: commandKeys}
+		for _, key := range reportSubscriptionKeys {
+			reportSubscriptionID, err := strconv.Atoi(key)
+			if err != nil {
+				return datastore.ErrInvalidData
+			}
+			references.ReportSubscriptions = append(references.ReportSubscriptions, reportSubscriptionID)
+		}
+		sort.Ints(references.ReportSubscriptions)
+
+		return &datastore.ReferencedError{References: references}
+	}
+
+	stateKeys, err := keysByEntityID(stateTable, id)
+	if err != nil {
+		return err
+	}
+
+	outboxKeys, err := pendingOutboxKeysByEntityID(outboxTable, id)
+	if err != nil {
+		return err
+	}
+
+	// every key has been decoded at this point, so nothing below can fail halfway
+	deleteKeys(commandTable, commandKeys)
+	deleteKeys(reportSubscriptionTable, reportSubscriptionKeys)
+	deleteKeys(stateTable, stateKeys)
+	deleteKeys(outboxTable, outboxKeys)
+	delete(entityTable, id)
+
+	return nil
+}
+
+// entityExists returns ErrRecordNotFound when the entity does not exist. The
+// lock must be held.
+func (s *DataStore) entityExists(id string) error {
+	table, ok := s.tables[tableEntity]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	if _, ok := table[id]; !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	return nil
+}
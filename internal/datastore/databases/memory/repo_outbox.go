@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+// outboxKey encodes a message ID in big endian, like BoltDB does, so sorted
+// keys follow the order messages were added.
+func outboxKey(id int) string {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+	return string(key)
+}
+
+// putOutboxMessages must be called with the datastore lock held. Nothing is
+// stored unless every message can be.
+func (s *DataStore) putOutboxMessages(messages []types.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	records := make(map[string][]byte, len(messages))
+	for _, message := range messages {
+		message.ID = s.nextSequence(tableOutbox)
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		records[outboxKey(message.ID)] = data
+	}
+
+	for key, data := range records {
+		table[key] = data
+	}
+
+	return nil
+}
+
+func (s *DataStore) AddOutboxMessages(messages ...types.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putOutboxMessages(messages)
+}
+
+func (s *DataStore) ListPendingOutboxMessages(limit int) ([]types.OutboxMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return nil, datastore.ErrTableDoesNotExist
+	}
+
+	var messageList []types.OutboxMessage
+	for _, key := range sortedKeys(table) {
+		if limit > 0 && len(messageList) >= limit {
+			break
+		}
+
+		var message types.OutboxMessage
+		if err := json.Unmarshal(table[key], &message); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if message.SentAt == nil {
+			messageList = append(messageList, message)
+		}
+	}
+
+	return messageList, nil
+}
+
+func (s *DataStore) ClaimPendingOutboxMessages(limit int, now time.Time, lease time.Duration) ([]types.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return nil, datastore.ErrTableDoesNotExist
+	}
+
+	claimedUntil := now.Add(lease)
+
+	var messageList []types.OutboxMessage
+	records := make(map[string][]byte)
+	for _, key := range sortedKeys(table) {
+		if limit > 0 && len(messageList) >= limit {
+			break
+		}
+
+		var message types.OutboxMessage
+		if err := json.Unmarshal(table[key], &message); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if message.SentAt != nil || (message.ClaimedUntil != nil && !message.ClaimedUntil.Before(now)) {
+			continue
+		}
+
+		message.ClaimedUntil = &claimedUntil
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		records[key] = data
+		messageList = append(messageList, message)
+	}
+
+	for key, data := range records {
+		table[key] = data
+	}
+
+	return messageList, nil
+}
+
+func (s *DataStore) ReleaseOutboxMessages(ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	records := make(map[string][]byte)
+	for _, id := range ids {
+		data, ok := table[outboxKey(id)]
+		if !ok {
+			continue
+		}
+
+		var message types.OutboxMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if message.SentAt != nil {
+			continue
+		}
+
+		message.ClaimedUntil = nil
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		records[outboxKey(id)] = data
+	}
+
+	for key, data := range records {
+		table[key] = data
+	}
+
+	return nil
+}
+
+// pendingOutboxKeysByEntityID returns the keys of the unsent messages of an
+// entity. It must be called with the datastore lock held.
+func pendingOutboxKeysByEntityID(table map[string][]byte, entityID string) ([]string, error) {
+	var keys []string
+
+	for _, key := range sortedKeys(table) {
+		var message types.OutboxMessage
+		if err := json.Unmarshal(table[key], &message); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if message.EntityID == entityID && message.SentAt == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *DataStore) MarkOutboxMessageSent(id int, sentAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[outboxKey(id)]
+	if !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	var message types.OutboxMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	message.SentAt = &sentAt
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	table[outboxKey(id)] = data
+	return nil
+}
+
+func (s *DataStore) DeleteSentOutboxMessages(sentBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableOutbox]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	var keys []string
+	for _, key := range sortedKeys(table) {
+		var message types.OutboxMessage
+		if err := json.Unmarshal(table[key], &message); err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		if message.SentAt != nil && message.SentAt.Before(sentBefore) {
+			keys = append(keys, key)
+		}
+	}
+
+	deleteKeys(table, keys)
+	return nil
+}
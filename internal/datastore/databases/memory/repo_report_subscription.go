@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func (s *DataStore) GetReportSubscriptionByID(id int) (types.ReportSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return types.ReportSubscription{}, datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[strconv.Itoa(id)]
+	if !ok {
+		return types.ReportSubscription{}, datastore.ErrRecordNotFound
+	}
+
+	var subscription types.ReportSubscription
+	if err := json.Unmarshal(data, &subscription); err != nil {
+		return types.ReportSubscription{}, datastore.ErrInvalidData
+	}
+
+	return subscription, nil
+}
+
+func (s *DataStore) ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription], options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return datastore.Page[types.ReportSubscription]{}, datastore.ErrTableDoesNotExist
+	}
+
+	var subscriptionList []types.ReportSubscription
+	for _, key := range sortedKeys(table) {
+		var subscription types.ReportSubscription
+
+		if err := json.Unmarshal(table[key], &subscription); err != nil {
+			return datastore.Page[types.ReportSubscription]{}, datastore.ErrInvalidData
+		}
+
+		if filter.Check(subscription) {
+			subscriptionList = append(subscriptionList, subscription)
+		}
+	}
+
+	return datastore.ReportSubscriptionOrdering.Paginate(subscriptionList, options)
+}
+
+func (s *DataStore) AddReportSubscription(reportSubscription types.ReportSubscription, announce ...datastore.ReportingAnnouncer) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return 0, datastore.ErrTableDoesNotExist
+	}
+
+	if err := s.entityExists(reportSubscription.EntityID); err != nil {
+		return 0, err
+	}
+
+	reportSubscription.ID = s.nextSequence(tableReportSubscription)
+
+	data, err := json.Marshal(reportSubscription)
+	if err != nil {
+		return 0, datastore.ErrInvalidData
+	}
+
+	if err := s.announceReporting(table, strconv.Itoa(reportSubscription.ID), data, reportSubscription.EntityID, announce); err != nil {
+		return 0, err
+	}
+
+	return reportSubscription.ID, nil
+}
+
+func (s *DataStore) DeleteReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[strconv.Itoa(id)]
+	if !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	var subscription types.ReportSubscription
+	if err := json.Unmarshal(data, &subscription); err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	return s.announceReporting(table, strconv.Itoa(id), nil, subscription.EntityID, announce)
+}
+
+func (s *DataStore) ActivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, true, announce)
+}
+
+func (s *DataStore) DeactivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, false, announce)
+}
+
+func (s *DataStore) setReportSubscriptionActive(id int, isActive bool, announce []datastore.ReportingAnnouncer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableReportSubscription]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	data, ok := table[strconv.Itoa(id)]
+	if !ok {
+		return datastore.ErrRecordNotFound
+	}
+
+	var subscription types.ReportSubscription
+	if err := json.Unmarshal(data, &subscription); err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	subscription.IsActive = isActive
+	subscription.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	return s.announceReporting(table, strconv.Itoa(id), data, subscription.EntityID, announce)
+}
+
+// announceReporting stores data under key, deleting the subscription when data
+// is nil, along with the outbox messages announcing the active subscriptions
+// of entityID after the change. Nothing is changed when announce fails. The
+// lock must be held.
+func (s *DataStore) announceReporting(table map[string][]byte, key string, data []byte, entityID string, announce []datastore.ReportingAnnouncer) error {
+	active := []types.ReportSubscription{}
+
+	isActive := func(data []byte) (types.ReportSubscription, bool, error) {
+		var subscription types.ReportSubscription
+		if err := json.Unmarshal(data, &subscription); err != nil {
+			return types.ReportSubscription{}, false, datastore.ErrInvalidData
+		}
+
+		return subscription, subscription.EntityID == entityID && subscription.IsActive, nil
+	}
+
+	if len(announce) > 0 {
+		for otherKey, otherData := range table {
+			if otherKey == key {
+				continue
+			}
+
+			subscription, ok, err := isActive(otherData)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				active = append(active, subscription)
+			}
+		}
+
+		if data != nil {
+			subscription, ok, err := isActive(data)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				active = append(active, subscription)
+			}
+		}
+
+		sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	}
+
+	var messages []types.OutboxMessage
+	for _, announcer := range announce {
+		announced, err := announcer(entityID, active)
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, announced...)
+	}
+
+	if err := s.putOutboxMessages(messages); err != nil {
+		return err
+	}
+
+	if data == nil {
+		delete(table, key)
+	} else {
+		table[key] = data
+	}
+
+	return nil
+}
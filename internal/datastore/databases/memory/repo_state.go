@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func (s *DataStore) GetLatestStateByEntityID(entityID string) (types.State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableState]
+	if !ok {
+		return types.State{}, datastore.ErrTableDoesNotExist
+	}
+
+	var latest *types.State
+	for _, key := range sortedKeys(table) {
+		var state types.State
+
+		if err := json.Unmarshal(table[key], &state); err != nil {
+			return types.State{}, datastore.ErrInvalidData
+		}
+
+		if state.EntityID != entityID {
+			continue
+		}
+
+		if latest == nil || state.ReportedAt.After(latest.ReportedAt) {
+			latest = &state
+		}
+	}
+
+	if latest == nil {
+		return types.State{}, datastore.ErrRecordNotFound
+	}
+
+	return *latest, nil
+}
+
+func (s *DataStore) ListStates(filter datastore.Filter[types.State]) ([]types.State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableState]
+	if !ok {
+		return nil, datastore.ErrTableDoesNotExist
+	}
+
+	var stateList []types.State
+	for _, key := range sortedKeys(table) {
+		var state types.State
+
+		if err := json.Unmarshal(table[key], &state); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if filter.Check(state) {
+			stateList = append(stateList, state)
+		}
+	}
+
+	return stateList, nil
+}
+
+func (s *DataStore) AddState(state types.State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableState]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	state.ID = s.nextSequence(tableState)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	table[strconv.Itoa(state.ID)] = data
+	return nil
+}
+
+func (s *DataStore) DeleteStatesByEntityID(entityID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, ok := s.tables[tableState]
+	if !ok {
+		return datastore.ErrTableDoesNotExist
+	}
+
+	keys, err := keysByEntityID(table, entityID)
+	if err != nil {
+		return err
+	}
+
+	deleteKeys(table, keys)
+	return nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/datastoretest"
+)
+
+// TestConformance runs against a local PostgreSQL server configured through
+// the ESR_DATASTORE_* environment variables, e.g.
+//
+//	ESR_TEST_POSTGRES=1 ESR_DATASTORE_HOST=localhost ESR_DATASTORE_USERNAME=postgres \
+//	ESR_DATASTORE_PASSWORD=postgres ESR_DATABASE_NAME=esr_test go test ./internal/datastore/databases/postgres
+//
+// Every table of the configured database is dropped between tests.
+func TestConformance(t *testing.T) {
+	if os.Getenv("ESR_TEST_POSTGRES") == "" {
+		t.Skip("ESR_TEST_POSTGRES is not set")
+	}
+
+	cfg := config.LoadConfig().DataStore
+
+	datastoretest.RunConformanceTests(t, func() datastore.DataStore {
+		store, err := NewPostgresDataStore(cfg)
+		if err != nil {
+			t.Errorf("failed to open datastore: %v", err)
+			return nil
+		}
+
+		if err := reset(cfg); err != nil {
+			t.Errorf("failed to reset datastore: %v", err)
+			store.Close()
+			return nil
+		}
+
+		return store
+	})
+}
+
+func reset(cfg config.DataStoreConfig) error {
+	db, err := sql.Open("pgx", dataSourceName(cfg))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`DROP TABLE IF EXISTS entities, commands, report_subscriptions, states, outbox, dead_letters, schema_migrations`)
+	return err
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/sqlstore"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	Name = "postgres"
+
+	defaultPort = 5432
+
+	// migrationLockKey identifies the advisory lock taken while migrating.
+	migrationLockKey = 0x657372 // "esr"
+
+	codeUniqueViolation = "23505"
+	codeUndefinedTable  = "42P01"
+)
+
+func NewPostgresDataStore(config config.DataStoreConfig) (*sqlstore.DataStore, error) {
+	db, err := sql.Open("pgx", dataSourceName(config))
+	if err != nil {
+		return nil, datastore.ErrConnectionFailed
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, datastore.ErrConnectionFailed
+	}
+
+	return sqlstore.New(db, dialect{}), nil
+}
+
+func dataSourceName(config config.DataStoreConfig) string {
+	port := config.Port
+	if port <= 0 {
+		port = defaultPort
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(config.Username, config.Password),
+		Host:   net.JoinHostPort(config.Host, strconv.Itoa(port)),
+		Path:   config.Name,
+	}
+
+	return dsn.String()
+}
+
+type dialect struct{}
+
+// Rebind numbers the placeholders, '$1', '$2'... Queries never have a '?'
+// other than their placeholders.
+func (dialect) Rebind(query string) string {
+	var (
+		rebound strings.Builder
+		n       int
+	)
+
+	for _, r := range query {
+		if r != '?' {
+			rebound.WriteRune(r)
+			continue
+		}
+
+		n++
+		rebound.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return rebound.String()
+}
+
+func (dialect) Time(t time.Time) any {
+	return t
+}
+
+func (dialect) JSON(data []byte) any {
+	return data
+}
+
+// MapError translates PostgreSQL errors into datastore errors.
+func (dialect) MapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeUniqueViolation:
+			return datastore.ErrDuplicateRecord
+		case codeUndefinedTable:
+			return datastore.ErrTableDoesNotExist
+		}
+	}
+
+	return nil
+}
+
+func (dialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
+
+func (dialect) SkipLocked() string {
+	return " FOR UPDATE SKIP LOCKED"
+}
+
+// OrderByBytes uses the "C" collation, the default one depends on the locale
+// of the database.
+func (dialect) OrderByBytes(column string) string {
+	return column + ` COLLATE "C"`
+}
+
+// LockMigrations takes a transaction level advisory lock, released on commit
+// or rollback, so replicas starting together migrate one after the other.
+func (dialect) LockMigrations(tx *sql.Tx) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockKey)
+	return err
+}
+
+func (dialect) ColumnTypes() sqlstore.ColumnTypes {
+	return sqlstore.ColumnTypes{
+		Serial: "INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		Bool:   "BOOLEAN",
+		Bytes:  "BYTEA",
+		JSON:   "JSONB",
+		Time:   "TIMESTAMPTZ",
+	}
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputQuery string
+		expected   string
+	}{
+		{
+			name:       "No Placeholders",
+			inputQuery: `SELECT id FROM entities`,
+			expected:   `SELECT id FROM entities`,
+		},
+		{
+			name:       "Placeholders",
+			inputQuery: ` WHERE status IN (?, ?) OR (entity_id = ? AND NOT COALESCE(entity_id = ?, FALSE))`,
+			expected:   ` WHERE status IN ($1, $2) OR (entity_id = $3 AND NOT COALESCE(entity_id = $4, FALSE))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dialect{}.Rebind(tt.inputQuery)
+
+			if got != tt.expected {
+				t.Errorf("Test failed. Expected: %q, Got: %q", tt.expected, got)
+			}
+		})
+	}
+}
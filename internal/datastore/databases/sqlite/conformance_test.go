@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/datastoretest"
+)
+
+func TestConformance(t *testing.T) {
+	dir := t.TempDir()
+	count := 0
+
+	datastoretest.RunConformanceTests(t, func() datastore.DataStore {
+		count++
+
+		store, err := NewSQLiteDataStore(config.DataStoreConfig{
+			Name: filepath.Join(dir, fmt.Sprintf("conformance%d", count)),
+		})
+		if err != nil {
+			t.Errorf("failed to open datastore: %v", err)
+			return nil
+		}
+
+		return store
+	})
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/sqlstore"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	Name = "sqlite"
+)
+
+func NewSQLiteDataStore(config config.DataStoreConfig) (*sqlstore.DataStore, error) {
+	// transactions take the write lock when they begin, and wait for other
+	// processes holding it, e.g. another instance migrating the database
+	path := fmt.Sprintf("file:%s.sqlite?_txlock=immediate&_pragma=busy_timeout(5000)", config.Name)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, datastore.ErrConnectionFailed
+	}
+
+	// SQLite allows a single writer, serializing connections avoids SQLITE_BUSY errors
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, datastore.ErrConnectionFailed
+	}
+
+	return sqlstore.New(db, dialect{}), nil
+}
+
+// dialect stores times as nanoseconds since the epoch, which keeps their
+// precision and sorts them as integers.
+type dialect struct{}
+
+func (dialect) Rebind(query string) string {
+	return query
+}
+
+func (dialect) Time(t time.Time) any {
+	return t.UnixNano()
+}
+
+func (dialect) JSON(data []byte) any {
+	return string(data)
+}
+
+// MapError translates SQLite errors into datastore errors.
+func (dialect) MapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			return datastore.ErrDuplicateRecord
+		}
+
+		if strings.Contains(sqliteErr.Error(), "no such table") {
+			return datastore.ErrTableDoesNotExist
+		}
+	}
+
+	return nil
+}
+
+// ForUpdate is empty, connections are serialized so a transaction never
+// sees the writes of another.
+func (dialect) ForUpdate() string {
+	return ""
+}
+
+func (dialect) SkipLocked() string {
+	return ""
+}
+
+// OrderByBytes keeps the column as is, SQLite compares text with memcmp by default.
+func (dialect) OrderByBytes(column string) string {
+	return column
+}
+
+// LockMigrations does not lock, transactions already hold the write lock of
+// the database.
+func (dialect) LockMigrations(*sql.Tx) error {
+	return nil
+}
+
+func (dialect) ColumnTypes() sqlstore.ColumnTypes {
+	return sqlstore.ColumnTypes{
+		Serial: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		Bool:   "INTEGER",
+		Bytes:  "BLOB",
+		JSON:   "TEXT",
+		Time:   "INTEGER",
+	}
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/config"
+)
+
+func TestConcurrentInit(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "esrdb")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			store, err := NewSQLiteDataStore(config.DataStoreConfig{Name: name})
+			if err != nil {
+				t.Errorf("failed to open datastore: %v", err)
+				return
+			}
+			defer store.Close()
+
+			if err := store.Init(); err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	store, err := NewSQLiteDataStore(config.DataStoreConfig{Name: name})
+	if err != nil {
+		t.Fatalf("failed to open datastore: %v", err)
+	}
+	defer store.Close()
+
+	statusList, err := store.MigrationStatus()
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	for _, status := range statusList {
+		if !status.Applied {
+			t.Errorf("Test failed. Migration was not applied: %+v", status)
+		}
+	}
+}
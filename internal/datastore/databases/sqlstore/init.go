@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+const (
+	createSchemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at  {time} NOT NULL
+	)`
+)
+
+func (s *DataStore) Init() error {
+	migrations := s.migrations()
+
+	if err := datastore.ValidateMigrations(migrations); err != nil {
+		return err
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range datastore.PendingMigrations(migrations, current) {
+		if err := s.applyMigration(migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies a migration unless it was applied since the schema
+// version was read, e.g. by another instance starting at the same time. The
+// version is checked again once the migration lock is held.
+func (s *DataStore) applyMigration(migration datastore.Migration[*sql.Tx]) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.dialect.LockMigrations(tx); err != nil {
+		return s.mapError(err)
+	}
+
+	if _, err := tx.Exec(s.ddl(createSchemaMigrationsTable)); err != nil {
+		return s.mapError(err)
+	}
+
+	current, err := s.schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	if current >= migration.Version {
+		return nil
+	}
+
+	if err := migration.Apply(tx); err != nil {
+		return s.mapError(err)
+	}
+
+	_, err = tx.Exec(
+		s.rebind(`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`),
+		migration.Version,
+		migration.Description,
+		s.dialect.Time(time.Now()),
+	)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+func (s *DataStore) SchemaVersion() (int, error) {
+	return s.schemaVersion(s.db)
+}
+
+func (s *DataStore) schemaVersion(q queryer) (int, error) {
+	var version int
+
+	err := q.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		err = s.mapError(err)
+		if errors.Is(err, datastore.ErrTableDoesNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (s *DataStore) MigrationStatus() ([]datastore.MigrationStatus, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return datastore.MigrationStatusList(s.migrations(), current), nil
+}
@@ -0,0 +1,122 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+// migrations must only ever be appended to, applied migrations are never run
+// again. Column types in braces are replaced by the types of the dialect.
+func (s *DataStore) migrations() []datastore.Migration[*sql.Tx] {
+	return []datastore.Migration[*sql.Tx]{
+		{
+			Version:     1,
+			Description: "create entity, command, report subscription and state tables",
+			Apply: s.execStatements(
+				`CREATE TABLE IF NOT EXISTS entities (
+					id   TEXT PRIMARY KEY,
+					name TEXT NOT NULL
+				)`,
+				`CREATE TABLE IF NOT EXISTS commands (
+					id            TEXT PRIMARY KEY,
+					entity_id     TEXT NOT NULL,
+					desired_state {json} NOT NULL,
+					status        TEXT NOT NULL,
+					issued_at     {time} NOT NULL,
+					timeout_at    {time},
+					resolved_at   {time},
+					reason        TEXT NOT NULL DEFAULT ''
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_commands_entity_id ON commands (entity_id)`,
+				`CREATE INDEX IF NOT EXISTS idx_commands_status ON commands (status)`,
+				`CREATE INDEX IF NOT EXISTS idx_commands_issued_at ON commands (issued_at)`,
+				`CREATE TABLE IF NOT EXISTS report_subscriptions (
+					id          {serial},
+					entity_id   TEXT NOT NULL,
+					report_type TEXT NOT NULL,
+					metric      TEXT,
+					is_active   {bool} NOT NULL,
+					updated_at  {time} NOT NULL
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_report_subscriptions_entity_id ON report_subscriptions (entity_id)`,
+				`CREATE TABLE IF NOT EXISTS states (
+					id          {serial},
+					entity_id   TEXT NOT NULL,
+					state       {json} NOT NULL,
+					reported_at {time} NOT NULL
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_states_entity_id_reported_at ON states (entity_id, reported_at)`,
+			),
+		},
+		{
+			Version:     2,
+			Description: "create outbox table",
+			Apply: s.execStatements(
+				`CREATE TABLE IF NOT EXISTS outbox (
+					id         {serial},
+					message_id TEXT NOT NULL,
+					topic      TEXT NOT NULL,
+					payload    {bytes} NOT NULL,
+					metadata   {json} NOT NULL,
+					created_at {time} NOT NULL,
+					sent_at    {time}
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (id) WHERE sent_at IS NULL`,
+			),
+		},
+		{
+			Version:     3,
+			Description: "create dead letter table",
+			Apply: s.execStatements(
+				`CREATE TABLE IF NOT EXISTS dead_letters (
+					id         {serial},
+					message_id TEXT NOT NULL,
+					handler    TEXT NOT NULL,
+					topic      TEXT NOT NULL,
+					payload    {bytes} NOT NULL,
+					metadata   {json} NOT NULL,
+					reason     TEXT NOT NULL,
+					created_at {time} NOT NULL
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_dead_letters_created_at ON dead_letters (created_at)`,
+			),
+		},
+		{
+			Version:     4,
+			Description: "add entity and claim to outbox messages",
+			Apply: s.execStatements(
+				`ALTER TABLE outbox ADD COLUMN entity_id TEXT NOT NULL DEFAULT ''`,
+				`ALTER TABLE outbox ADD COLUMN claimed_until {time}`,
+				`CREATE INDEX IF NOT EXISTS idx_outbox_entity_id ON outbox (entity_id) WHERE sent_at IS NULL`,
+			),
+		},
+	}
+}
+
+func (s *DataStore) execStatements(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(s.ddl(statement)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// ddl replaces the column types in braces of a statement with the types of
+// the dialect.
+func (s *DataStore) ddl(statement string) string {
+	types := s.dialect.ColumnTypes()
+
+	return strings.NewReplacer(
+		"{serial}", types.Serial,
+		"{bool}", types.Bool,
+		"{bytes}", types.Bytes,
+		"{json}", types.JSON,
+		"{time}", types.Time,
+	).Replace(statement)
+}
@@ -0,0 +1,58 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+// pageClauses extends a WHERE clause built by whereClause with the keyset
+// condition of position, then adds the ORDER BY clause of the page and, when
+// limit is set, its LIMIT. sortColumns maps every sort field, including
+// datastore.SortByID, to the expression it is ordered by.
+func (s *DataStore) pageClauses(
+	where string,
+	args []any,
+	options datastore.ListOptions,
+	position *datastore.Position,
+	sortColumns map[string]string,
+	limit bool,
+) (string, []any) {
+	column := sortColumns[options.Sort.Field]
+	idColumn := sortColumns[datastore.SortByID]
+
+	direction, operator := "ASC", ">"
+	if options.Sort.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	if position != nil {
+		var keyset string
+		if options.Sort.Field == datastore.SortByID {
+			keyset = fmt.Sprintf("%s %s ?", idColumn, operator)
+			args = append(args, s.sqlValue(position.ID))
+		} else {
+			keyset = fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", column, operator, idColumn)
+			args = append(args, s.sqlValue(position.Value), s.sqlValue(position.Value), s.sqlValue(position.ID))
+		}
+
+		if where == "" {
+			where = " WHERE " + keyset
+		} else {
+			// the filter may be a disjunction, keep it apart from the keyset
+			where = " WHERE (" + strings.TrimPrefix(where, " WHERE ") + ") AND " + keyset
+		}
+	}
+
+	clauses := where + fmt.Sprintf(" ORDER BY %s %s", column, direction)
+	if options.Sort.Field != datastore.SortByID {
+		clauses += fmt.Sprintf(", %s %s", idColumn, direction)
+	}
+
+	if limit && options.Limit > 0 {
+		clauses += fmt.Sprintf(" LIMIT %d", options.Limit+1)
+	}
+
+	return clauses, args
+}
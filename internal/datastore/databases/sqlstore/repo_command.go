@@ -0,0 +1,203 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	commandColumns = `id, entity_id, desired_state, status, issued_at, timeout_at, resolved_at, reason`
+)
+
+var commandFilterColumns = map[string]string{
+	filters.FieldEntityID:  "entity_id",
+	filters.FieldStatus:    "status",
+	filters.FieldIssuedAt:  "issued_at",
+	filters.FieldTimeoutAt: "timeout_at",
+}
+
+func (s *DataStore) commandSortColumns() map[string]string {
+	return map[string]string{
+		datastore.SortByID:       s.dialect.OrderByBytes(`id`),
+		datastore.SortByIssuedAt: `issued_at`,
+	}
+}
+
+func scanCommand(row scanner) (types.Command, error) {
+	var (
+		command      types.Command
+		desiredState []byte
+		issuedAt     nullTime
+		timeoutAt    nullTime
+		resolvedAt   nullTime
+	)
+
+	err := row.Scan(
+		&command.ID,
+		&command.EntityID,
+		&desiredState,
+		&command.Status,
+		&issuedAt,
+		&timeoutAt,
+		&resolvedAt,
+		&command.Reason,
+	)
+	if err != nil {
+		return types.Command{}, err
+	}
+
+	if err := json.Unmarshal(desiredState, &command.DesiredState); err != nil {
+		return types.Command{}, datastore.ErrInvalidData
+	}
+
+	command.IssuedAt = issuedAt.Time
+	command.TimeoutAt = timeoutAt.Ptr()
+	command.ResolvedAt = resolvedAt.Ptr()
+
+	return command, nil
+}
+
+func (s *DataStore) GetCommandByID(id string) (types.Command, error) {
+	row := s.db.QueryRow(s.rebind(`SELECT `+commandColumns+` FROM commands WHERE id = ?`), id)
+
+	command, err := scanCommand(row)
+	if err != nil {
+		return types.Command{}, s.mapError(err)
+	}
+
+	return command, nil
+}
+
+func (s *DataStore) ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error) {
+	options, err := datastore.CommandOrdering.Normalize(options)
+	if err != nil {
+		return datastore.Page[types.Command]{}, err
+	}
+
+	position, err := datastore.CommandOrdering.Position(options)
+	if err != nil {
+		return datastore.Page[types.Command]{}, err
+	}
+
+	where, args, translated := s.whereClause(filter, commandFilterColumns)
+	clauses, args := s.pageClauses(where, args, options, position, s.commandSortColumns(), translated)
+
+	rows, err := s.db.Query(s.rebind(`SELECT `+commandColumns+` FROM commands`+clauses), args...)
+	if err != nil {
+		return datastore.Page[types.Command]{}, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var commandList []types.Command
+	for rows.Next() {
+		command, err := scanCommand(rows)
+		if err != nil {
+			return datastore.Page[types.Command]{}, datastore.ErrInvalidData
+		}
+
+		if translated || filter.Check(command) {
+			commandList = append(commandList, command)
+			if options.Limit > 0 && len(commandList) > options.Limit {
+				break
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return datastore.Page[types.Command]{}, s.mapError(err)
+	}
+
+	return datastore.CommandOrdering.NewPage(options, commandList), nil
+}
+
+func (s *DataStore) AddCommand(command types.Command, outbox ...types.OutboxMessage) error {
+	desiredState, err := json.Marshal(command.DesiredState)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.lockEntity(tx, command.EntityID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		s.rebind(`INSERT INTO commands (`+commandColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			entity_id = EXCLUDED.entity_id,
+			desired_state = EXCLUDED.desired_state,
+			status = EXCLUDED.status,
+			issued_at = EXCLUDED.issued_at,
+			timeout_at = EXCLUDED.timeout_at,
+			resolved_at = EXCLUDED.resolved_at,
+			reason = EXCLUDED.reason`),
+		command.ID,
+		command.EntityID,
+		s.dialect.JSON(desiredState),
+		string(command.Status),
+		s.dialect.Time(command.IssuedAt),
+		s.nullTime(command.TimeoutAt),
+		s.nullTime(command.ResolvedAt),
+		command.Reason,
+	)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	if err := s.insertOutboxMessages(tx, outbox); err != nil {
+		return err
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+func (s *DataStore) ResolveCommand(id string, status types.CommandStatus, reason string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// only a pending command is resolved, so a command resolved concurrently,
+	// e.g. by a state report and a timeout, keeps its first resolution
+	result, err := tx.Exec(
+		s.rebind(`UPDATE commands SET status = ?, resolved_at = ?, reason = ? WHERE id = ? AND status = ?`),
+		string(status),
+		s.dialect.Time(time.Now()),
+		reason,
+		id,
+		string(types.CommandStatusPending),
+	)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	if err := expectAffected(result); err != nil {
+		var exists int
+		if err := tx.QueryRow(s.rebind(`SELECT 1 FROM commands WHERE id = ?`), id).Scan(&exists); err != nil {
+			return s.mapError(err)
+		}
+
+		return datastore.ErrAlreadyResolved
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+func (s *DataStore) DeleteCommand(id string) error {
+	result, err := s.db.Exec(s.rebind(`DELETE FROM commands WHERE id = ?`), id)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	return expectAffected(result)
+}
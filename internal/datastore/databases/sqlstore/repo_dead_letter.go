@@ -0,0 +1,137 @@
+package sqlstore
+
+import (
+	"encoding/json"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	deadLetterColumns = `id, message_id, handler, topic, payload, metadata, reason, created_at`
+)
+
+var deadLetterSortColumns = map[string]string{
+	datastore.SortByID:        `id`,
+	datastore.SortByCreatedAt: `created_at`,
+}
+
+func scanDeadLetter(row scanner) (types.DeadLetter, error) {
+	var (
+		deadLetter types.DeadLetter
+		metadata   []byte
+		createdAt  nullTime
+	)
+
+	err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.MessageID,
+		&deadLetter.Handler,
+		&deadLetter.Topic,
+		&deadLetter.Payload,
+		&metadata,
+		&deadLetter.Reason,
+		&createdAt,
+	)
+	if err != nil {
+		return types.DeadLetter{}, err
+	}
+
+	if err := json.Unmarshal(metadata, &deadLetter.Metadata); err != nil {
+		return types.DeadLetter{}, datastore.ErrInvalidData
+	}
+
+	deadLetter.CreatedAt = createdAt.Time
+
+	return deadLetter, nil
+}
+
+func (s *DataStore) GetDeadLetterByID(id int) (types.DeadLetter, error) {
+	row := s.db.QueryRow(s.rebind(`SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = ?`), id)
+
+	deadLetter, err := scanDeadLetter(row)
+	if err != nil {
+		return types.DeadLetter{}, s.mapError(err)
+	}
+
+	return deadLetter, nil
+}
+
+func (s *DataStore) ListDeadLetters(options datastore.ListOptions) (datastore.Page[types.DeadLetter], error) {
+	options, err := datastore.DeadLetterOrdering.Normalize(options)
+	if err != nil {
+		return datastore.Page[types.DeadLetter]{}, err
+	}
+
+	position, err := datastore.DeadLetterOrdering.Position(options)
+	if err != nil {
+		return datastore.Page[types.DeadLetter]{}, err
+	}
+
+	clauses, args := s.pageClauses("", nil, options, position, deadLetterSortColumns, true)
+
+	rows, err := s.db.Query(s.rebind(`SELECT `+deadLetterColumns+` FROM dead_letters`+clauses), args...)
+	if err != nil {
+		return datastore.Page[types.DeadLetter]{}, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var deadLetterList []types.DeadLetter
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows)
+		if err != nil {
+			return datastore.Page[types.DeadLetter]{}, datastore.ErrInvalidData
+		}
+
+		deadLetterList = append(deadLetterList, deadLetter)
+		if options.Limit > 0 && len(deadLetterList) > options.Limit {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return datastore.Page[types.DeadLetter]{}, s.mapError(err)
+	}
+
+	return datastore.DeadLetterOrdering.NewPage(options, deadLetterList), nil
+}
+
+func (s *DataStore) AddDeadLetter(deadLetter types.DeadLetter) (int, error) {
+	metadata, err := json.Marshal(deadLetter.Metadata)
+	if err != nil {
+		return 0, datastore.ErrInvalidData
+	}
+
+	payload := deadLetter.Payload
+	if payload == nil {
+		payload = []byte{}
+	}
+
+	var id int
+
+	err = s.db.QueryRow(
+		s.rebind(`INSERT INTO dead_letters (message_id, handler, topic, payload, metadata, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+		deadLetter.MessageID,
+		deadLetter.Handler,
+		deadLetter.Topic,
+		payload,
+		s.dialect.JSON(metadata),
+		deadLetter.Reason,
+		s.dialect.Time(deadLetter.CreatedAt),
+	).Scan(&id)
+	if err != nil {
+		return 0, s.mapError(err)
+	}
+
+	return id, nil
+}
+
+func (s *DataStore) DeleteDeadLetter(id int) error {
+	result, err := s.db.Exec(s.rebind(`DELETE FROM dead_letters WHERE id = ?`), id)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	return expectAffected(result)
+}
@@ -0,0 +1,169 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	entityColumns = `id, name`
+)
+
+func (s *DataStore) entitySortColumns() map[string]string {
+	return map[string]string{
+		datastore.SortByID:   s.dialect.OrderByBytes(`id`),
+		datastore.SortByName: s.dialect.OrderByBytes(`name`),
+	}
+}
+
+func (s *DataStore) GetEntityByID(id string) (types.Entity, error) {
+	var entity types.Entity
+
+	row := s.db.QueryRow(s.rebind(`SELECT `+entityColumns+` FROM entities WHERE id = ?`), id)
+	if err := row.Scan(&entity.ID, &entity.Name); err != nil {
+		return types.Entity{}, s.mapError(err)
+	}
+
+	return entity, nil
+}
+
+func (s *DataStore) ListEntities(options datastore.ListOptions) (datastore.Page[types.Entity], error) {
+	options, err := datastore.EntityOrdering.Normalize(options)
+	if err != nil {
+		return datastore.Page[types.Entity]{}, err
+	}
+
+	position, err := datastore.EntityOrdering.Position(options)
+	if err != nil {
+		return datastore.Page[types.Entity]{}, err
+	}
+
+	clauses, args := s.pageClauses("", nil, options, position, s.entitySortColumns(), true)
+
+	rows, err := s.db.Query(s.rebind(`SELECT `+entityColumns+` FROM entities`+clauses), args...)
+	if err != nil {
+		return datastore.Page[types.Entity]{}, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var entityList []types.Entity
+	for rows.Next() {
+		var entity types.Entity
+
+		if err := rows.Scan(&entity.ID, &entity.Name); err != nil {
+			return datastore.Page[types.Entity]{}, datastore.ErrInvalidData
+		}
+
+		entityList = append(entityList, entity)
+		if options.Limit > 0 && len(entityList) > options.Limit {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return datastore.Page[types.Entity]{}, s.mapError(err)
+	}
+
+	return datastore.EntityOrdering.NewPage(options, entityList), nil
+}
+
+func (s *DataStore) AddEntity(entity types.Entity) error {
+	_, err := s.db.Exec(s.rebind(`INSERT INTO entities (id, name) VALUES (?, ?)`), entity.ID, entity.Name)
+	return s.mapError(err)
+}
+
+func (s *DataStore) DeleteEntity(id string, policy datastore.DeletionPolicy) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.lockEntity(tx, id); err != nil {
+		return err
+	}
+
+	if policy != datastore.DeletionPolicyCascade {
+		references, err := s.entityReferences(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !references.IsEmpty() {
+			return &datastore.ReferencedError{References: references}
+		}
+	}
+
+	statements := []string{
+		`DELETE FROM commands WHERE entity_id = ?`,
+		`DELETE FROM report_subscriptions WHERE entity_id = ?`,
+		`DELETE FROM states WHERE entity_id = ?`,
+		`DELETE FROM outbox WHERE entity_id = ? AND sent_at IS NULL`,
+		`DELETE FROM entities WHERE id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(s.rebind(statement), id); err != nil {
+			return s.mapError(err)
+		}
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+func (s *DataStore) entityReferences(tx *sql.Tx, id string) (types.EntityReferences, error) {
+	var (
+		references types.EntityReferences
+		err        error
+	)
+
+	references.Commands, err = queryIDs[string](s, tx, `SELECT id FROM commands WHERE entity_id = ? ORDER BY id`, id)
+	if err != nil {
+		return types.EntityReferences{}, err
+	}
+
+	references.ReportSubscriptions, err = queryIDs[int](s, tx, `SELECT id FROM report_subscriptions WHERE entity_id = ? ORDER BY id`, id)
+	if err != nil {
+		return types.EntityReferences{}, err
+	}
+
+	return references, nil
+}
+
+func queryIDs[T any](s *DataStore, tx *sql.Tx, query string, args ...any) ([]T, error) {
+	rows, err := tx.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var ids []T
+	for rows.Next() {
+		var id T
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+
+	return ids, nil
+}
+
+// lockEntity returns ErrRecordNotFound when the entity does not exist, and
+// otherwise keeps it from being deleted, or records from being added to it
+// while it is deleted, until tx ends.
+func (s *DataStore) lockEntity(tx *sql.Tx, id string) error {
+	var exists int
+	if err := tx.QueryRow(s.rebind(`SELECT 1 FROM entities WHERE id = ?`+s.dialect.ForUpdate()), id).Scan(&exists); err != nil {
+		return s.mapError(err)
+	}
+
+	return nil
+}
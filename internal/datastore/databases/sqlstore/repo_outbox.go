@@ -0,0 +1,208 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	outboxColumns = `id, message_id, entity_id, topic, payload, metadata, created_at, sent_at, claimed_until`
+)
+
+func scanOutboxMessage(row scanner) (types.OutboxMessage, error) {
+	var (
+		message      types.OutboxMessage
+		metadata     []byte
+		createdAt    nullTime
+		sentAt       nullTime
+		claimedUntil nullTime
+	)
+
+	err := row.Scan(
+		&message.ID,
+		&message.MessageID,
+		&message.EntityID,
+		&message.Topic,
+		&message.Payload,
+		&metadata,
+		&createdAt,
+		&sentAt,
+		&claimedUntil,
+	)
+	if err != nil {
+		return types.OutboxMessage{}, err
+	}
+
+	if err := json.Unmarshal(metadata, &message.Metadata); err != nil {
+		return types.OutboxMessage{}, datastore.ErrInvalidData
+	}
+
+	message.CreatedAt = createdAt.Time
+	message.SentAt = sentAt.Ptr()
+	message.ClaimedUntil = claimedUntil.Ptr()
+
+	return message, nil
+}
+
+func scanOutboxMessages(rows *sql.Rows) ([]types.OutboxMessage, error) {
+	var messageList []types.OutboxMessage
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows)
+		if err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		messageList = append(messageList, message)
+	}
+
+	return messageList, nil
+}
+
+// insertOutboxMessages adds messages to the outbox within an existing transaction.
+func (s *DataStore) insertOutboxMessages(tx *sql.Tx, messages []types.OutboxMessage) error {
+	for _, message := range messages {
+		metadata, err := json.Marshal(message.Metadata)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		payload := message.Payload
+		if payload == nil {
+			payload = []byte{}
+		}
+
+		_, err = tx.Exec(
+			s.rebind(`INSERT INTO outbox (message_id, entity_id, topic, payload, metadata, created_at, sent_at, claimed_until) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			message.MessageID,
+			message.EntityID,
+			message.Topic,
+			payload,
+			s.dialect.JSON(metadata),
+			s.dialect.Time(message.CreatedAt),
+			s.nullTime(message.SentAt),
+			s.nullTime(message.ClaimedUntil),
+		)
+		if err != nil {
+			return s.mapError(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *DataStore) AddOutboxMessages(messages ...types.OutboxMessage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.insertOutboxMessages(tx, messages); err != nil {
+		return err
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+func (s *DataStore) ListPendingOutboxMessages(limit int) ([]types.OutboxMessage, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE sent_at IS NULL ORDER BY id`
+	args := []any{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	messageList, err := scanOutboxMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+
+	return messageList, nil
+}
+
+func (s *DataStore) ClaimPendingOutboxMessages(limit int, now time.Time, lease time.Duration) ([]types.OutboxMessage, error) {
+	// the claimable condition is checked again on the rows to update, in case
+	// another relay claimed them since they were selected
+	claimable := `sent_at IS NULL AND (claimed_until IS NULL OR claimed_until < ?)`
+
+	selectQuery := `SELECT id FROM outbox WHERE ` + claimable + ` ORDER BY id`
+	args := []any{s.dialect.Time(now.Add(lease)), s.dialect.Time(now)}
+	if limit > 0 {
+		selectQuery += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	selectQuery += s.dialect.SkipLocked()
+	args = append(args, s.dialect.Time(now))
+
+	query := `UPDATE outbox SET claimed_until = ? WHERE id IN (` + selectQuery + `) AND ` + claimable + ` RETURNING ` + outboxColumns
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	messageList, err := scanOutboxMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(messageList, func(i, j int) bool {
+		return messageList[i].ID < messageList[j].ID
+	})
+
+	return messageList, nil
+}
+
+func (s *DataStore) ReleaseOutboxMessages(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(ids))
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+
+	query := `UPDATE outbox SET claimed_until = NULL WHERE sent_at IS NULL AND id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	_, err := s.db.Exec(s.rebind(query), args...)
+	return s.mapError(err)
+}
+
+func (s *DataStore) MarkOutboxMessageSent(id int, sentAt time.Time) error {
+	result, err := s.db.Exec(s.rebind(`UPDATE outbox SET sent_at = ? WHERE id = ?`), s.dialect.Time(sentAt), id)
+	if err != nil {
+		return s.mapError(err)
+	}
+
+	return expectAffected(result)
+}
+
+func (s *DataStore) DeleteSentOutboxMessages(sentBefore time.Time) error {
+	_, err := s.db.Exec(s.rebind(`DELETE FROM outbox WHERE sent_at < ?`), s.dialect.Time(sentBefore))
+	return s.mapError(err)
+}
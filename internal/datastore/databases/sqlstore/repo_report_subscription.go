@@ -0,0 +1,235 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	reportSubscriptionColumns = `id, entity_id, report_type, metric, is_active, updated_at`
+)
+
+var reportSubscriptionFilterColumns = map[string]string{
+	filters.FieldEntityID:   "entity_id",
+	filters.FieldReportType: "report_type",
+	filters.FieldIsActive:   "is_active",
+	filters.FieldUpdatedAt:  "updated_at",
+}
+
+var reportSubscriptionSortColumns = map[string]string{
+	datastore.SortByID:        `id`,
+	datastore.SortByUpdatedAt: `updated_at`,
+}
+
+func scanReportSubscription(row scanner) (types.ReportSubscription, error) {
+	var (
+		subscription types.ReportSubscription
+		metric       sql.NullString
+		updatedAt    nullTime
+	)
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.EntityID,
+		&subscription.ReportType,
+		&metric,
+		&subscription.IsActive,
+		&updatedAt,
+	)
+	if err != nil {
+		return types.ReportSubscription{}, err
+	}
+
+	if metric.Valid {
+		subscription.Metric = &metric.String
+	}
+	subscription.UpdatedAt = updatedAt.Time
+
+	return subscription, nil
+}
+
+func (s *DataStore) GetReportSubscriptionByID(id int) (types.ReportSubscription, error) {
+	row := s.db.QueryRow(s.rebind(`SELECT `+reportSubscriptionColumns+` FROM report_subscriptions WHERE id = ?`), id)
+
+	subscription, err := scanReportSubscription(row)
+	if err != nil {
+		return types.ReportSubscription{}, s.mapError(err)
+	}
+
+	return subscription, nil
+}
+
+func (s *DataStore) ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription], options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error) {
+	options, err := datastore.ReportSubscriptionOrdering.Normalize(options)
+	if err != nil {
+		return datastore.Page[types.ReportSubscription]{}, err
+	}
+
+	position, err := datastore.ReportSubscriptionOrdering.Position(options)
+	if err != nil {
+		return datastore.Page[types.ReportSubscription]{}, err
+	}
+
+	where, args, translated := s.whereClause(filter, reportSubscriptionFilterColumns)
+	clauses, args := s.pageClauses(where, args, options, position, reportSubscriptionSortColumns, translated)
+
+	rows, err := s.db.Query(s.rebind(`SELECT `+reportSubscriptionColumns+` FROM report_subscriptions`+clauses), args...)
+	if err != nil {
+		return datastore.Page[types.ReportSubscription]{}, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var subscriptionList []types.ReportSubscription
+	for rows.Next() {
+		subscription, err := scanReportSubscription(rows)
+		if err != nil {
+			return datastore.Page[types.ReportSubscription]{}, datastore.ErrInvalidData
+		}
+
+		if translated || filter.Check(subscription) {
+			subscriptionList = append(subscriptionList, subscription)
+			if options.Limit > 0 && len(subscriptionList) > options.Limit {
+				break
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return datastore.Page[types.ReportSubscription]{}, s.mapError(err)
+	}
+
+	return datastore.ReportSubscriptionOrdering.NewPage(options, subscriptionList), nil
+}
+
+func (s *DataStore) AddReportSubscription(reportSubscription types.ReportSubscription, announce ...datastore.ReportingAnnouncer) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.lockEntity(tx, reportSubscription.EntityID); err != nil {
+		return 0, err
+	}
+
+	var id int
+
+	err = tx.QueryRow(
+		s.rebind(`INSERT INTO report_subscriptions (entity_id, report_type, metric, is_active, updated_at)
+		VALUES (?, ?, ?, ?, ?) RETURNING id`),
+		reportSubscription.EntityID,
+		string(reportSubscription.ReportType),
+		reportSubscription.Metric,
+		reportSubscription.IsActive,
+		s.dialect.Time(reportSubscription.UpdatedAt),
+	).Scan(&id)
+	if err != nil {
+		return 0, s.mapError(err)
+	}
+
+	if err := s.announceReporting(tx, reportSubscription.EntityID, announce); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, s.mapError(err)
+	}
+
+	return id, nil
+}
+
+func (s *DataStore) DeleteReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.changeReportSubscription(announce, `DELETE FROM report_subscriptions WHERE id = ? RETURNING entity_id`, id)
+}
+
+func (s *DataStore) ActivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, true, announce)
+}
+
+func (s *DataStore) DeactivateReportSubscription(id int, announce ...datastore.ReportingAnnouncer) error {
+	return s.setReportSubscriptionActive(id, false, announce)
+}
+
+func (s *DataStore) setReportSubscriptionActive(id int, isActive bool, announce []datastore.ReportingAnnouncer) error {
+	return s.changeReportSubscription(
+		announce,
+		`UPDATE report_subscriptions SET is_active = ?, updated_at = ? WHERE id = ? RETURNING entity_id`,
+		isActive,
+		s.dialect.Time(time.Now()),
+		id,
+	)
+}
+
+// changeReportSubscription runs query, which changes a single subscription and
+// returns its entity ID, and announces the subscriptions of that entity in the
+// same transaction.
+func (s *DataStore) changeReportSubscription(announce []datastore.ReportingAnnouncer, query string, args ...any) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var entityID string
+	if err := tx.QueryRow(s.rebind(query), args...).Scan(&entityID); err != nil {
+		return s.mapError(err)
+	}
+
+	if err := s.announceReporting(tx, entityID, announce); err != nil {
+		return err
+	}
+
+	return s.mapError(tx.Commit())
+}
+
+// announceReporting stores the outbox messages announcing the active
+// subscriptions of entityID, as they are in tx.
+func (s *DataStore) announceReporting(tx *sql.Tx, entityID string, announce []datastore.ReportingAnnouncer) error {
+	if len(announce) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(
+		s.rebind(`SELECT `+reportSubscriptionColumns+` FROM report_subscriptions WHERE entity_id = ? AND is_active = ? ORDER BY id`),
+		entityID,
+		true,
+	)
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer rows.Close()
+
+	active := []types.ReportSubscription{}
+	for rows.Next() {
+		subscription, err := scanReportSubscription(rows)
+		if err != nil {
+			return datastore.ErrInvalidData
+		}
+
+		active = append(active, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return s.mapError(err)
+	}
+
+	// the rows must be closed before the outbox messages are inserted
+	rows.Close()
+
+	for _, announcer := range announce {
+		messages, err := announcer(entityID, active)
+		if err != nil {
+			return err
+		}
+
+		if err := s.insertOutboxMessages(tx, messages); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"encoding/json"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	stateColumns = `id, entity_id, state, reported_at`
+)
+
+var stateFilterColumns = map[string]string{
+	filters.FieldEntityID:   "entity_id",
+	filters.FieldReportedAt: "reported_at",
+}
+
+func scanState(row scanner) (types.State, error) {
+	var (
+		state      types.State
+		data       []byte
+		reportedAt nullTime
+	)
+
+	if err := row.Scan(&state.ID, &state.EntityID, &data, &reportedAt); err != nil {
+		return types.State{}, err
+	}
+
+	if err := json.Unmarshal(data, &state.State); err != nil {
+		return types.State{}, datastore.ErrInvalidData
+	}
+
+	state.ReportedAt = reportedAt.Time
+
+	return state, nil
+}
+
+func (s *DataStore) GetLatestStateByEntityID(entityID string) (types.State, error) {
+	row := s.db.QueryRow(
+		s.rebind(`SELECT `+stateColumns+` FROM states WHERE entity_id = ? ORDER BY reported_at DESC, id DESC LIMIT 1`),
+		entityID,
+	)
+
+	state, err := scanState(row)
+	if err != nil {
+		return types.State{}, s.mapError(err)
+	}
+
+	return state, nil
+}
+
+func (s *DataStore) ListStates(filter datastore.Filter[types.State]) ([]types.State, error) {
+	where, args, translated := s.whereClause(filter, stateFilterColumns)
+
+	rows, err := s.db.Query(s.rebind(`SELECT `+stateColumns+` FROM states`+where+` ORDER BY id`), args...)
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+	defer rows.Close()
+
+	var stateList []types.State
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, datastore.ErrInvalidData
+		}
+
+		if translated || filter.Check(state) {
+			stateList = append(stateList, state)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.mapError(err)
+	}
+
+	return stateList, nil
+}
+
+func (s *DataStore) AddState(state types.State) error {
+	data, err := json.Marshal(state.State)
+	if err != nil {
+		return datastore.ErrInvalidData
+	}
+
+	_, err = s.db.Exec(
+		s.rebind(`INSERT INTO states (entity_id, state, reported_at) VALUES (?, ?, ?)`),
+		state.EntityID,
+		s.dialect.JSON(data),
+		s.dialect.Time(state.ReportedAt),
+	)
+
+	return s.mapError(err)
+}
+
+func (s *DataStore) DeleteStatesByEntityID(entityID string) error {
+	_, err := s.db.Exec(s.rebind(`DELETE FROM states WHERE entity_id = ?`), entityID)
+	return s.mapError(err)
+}
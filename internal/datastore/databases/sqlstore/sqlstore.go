@@ -0,0 +1,155 @@
+// Package sqlstore implements the datastore on top of database/sql. The SQL
+// databases only differ by their Dialect.
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+// Dialect is what sets a SQL database apart from the others. Queries are
+// written with '?' placeholders and in the SQL both SQLite and PostgreSQL
+// understand.
+type Dialect interface {
+	// Rebind replaces the '?' placeholders of a query with the ones of the database.
+	Rebind(query string) string
+	// Time encodes a time the way the database stores it.
+	Time(t time.Time) any
+	// JSON encodes a JSON document the way the database stores it.
+	JSON(data []byte) any
+	// MapError translates a driver error into a datastore error, or returns
+	// nil when it does not know it.
+	MapError(err error) error
+	// ForUpdate is appended to a SELECT to lock the rows it reads until the
+	// end of the transaction, when the database locks rows.
+	ForUpdate() string
+	// SkipLocked is appended to a SELECT to lock the rows it reads, skipping
+	// the rows locked by other transactions, when the database locks rows.
+	SkipLocked() string
+	// OrderByBytes makes a text column compare byte by byte, like the keys
+	// of the other datastores.
+	OrderByBytes(column string) string
+	// LockMigrations blocks until no other instance migrates the database, and
+	// holds the lock until the end of the transaction.
+	LockMigrations(tx *sql.Tx) error
+	// ColumnTypes are the types of the columns created by the migrations.
+	ColumnTypes() ColumnTypes
+}
+
+// ColumnTypes maps the generic column types of the migrations to the types of
+// a database.
+type ColumnTypes struct {
+	// Serial is an auto-incremented integer primary key.
+	Serial string
+	Bool   string
+	Bytes  string
+	JSON   string
+	Time   string
+}
+
+// DataStore represents a SQL datastore
+type DataStore struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func New(db *sql.DB, dialect Dialect) *DataStore {
+	return &DataStore{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+func (s *DataStore) Close() {
+	_ = s.db.Close()
+}
+
+func (s *DataStore) rebind(query string) string {
+	return s.dialect.Rebind(query)
+}
+
+func (s *DataStore) nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return s.dialect.Time(*t)
+}
+
+// mapError translates database errors into datastore errors.
+func (s *DataStore) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return datastore.ErrRecordNotFound
+	}
+
+	if errors.Is(err, datastore.ErrInvalidData) {
+		return err
+	}
+
+	if mapped := s.dialect.MapError(err); mapped != nil {
+		return mapped
+	}
+
+	return datastore.ErrTransactionFailed
+}
+
+// expectAffected returns ErrRecordNotFound when a statement did not touch any row.
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return datastore.ErrTransactionFailed
+	}
+
+	if affected == 0 {
+		return datastore.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is either a *sql.DB or a *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// nullTime scans a time as stored by any dialect, either as a timestamp or
+// as nanoseconds since the epoch.
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (t *nullTime) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time, t.Valid = time.Time{}, false
+	case time.Time:
+		t.Time, t.Valid = v.UTC(), true
+	case int64:
+		t.Time, t.Valid = time.Unix(0, v).UTC(), true
+	default:
+		return fmt.Errorf("%w: unsupported time %T", datastore.ErrInvalidData, src)
+	}
+
+	return nil
+}
+
+func (t nullTime) Ptr() *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
@@ -0,0 +1,144 @@
+package sqlstore
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+)
+
+// whereClause translates a filter into a SQL WHERE clause using the given
+// field to column mapping. It reports false when the filter cannot be fully
+// translated, in which case the caller has to evaluate it in Go.
+func (s *DataStore) whereClause(filter any, columns map[string]string) (string, []any, bool) {
+	expression, ok := filters.ExpressionOf(filter)
+	if !ok {
+		return "", nil, false
+	}
+
+	if and, ok := expression.(filters.AndExpression); ok && len(and) == 0 {
+		return "", nil, true
+	}
+
+	var args []any
+
+	clause, ok := s.renderExpression(expression, columns, &args)
+	if !ok {
+		return "", nil, false
+	}
+
+	return " WHERE " + clause, args, true
+}
+
+func (s *DataStore) renderExpression(expression filters.Expression, columns map[string]string, args *[]any) (string, bool) {
+	switch e := expression.(type) {
+	case filters.Condition:
+		return s.renderCondition(e, columns, args)
+	case filters.AndExpression:
+		return s.renderOperands(e, " AND ", "TRUE", columns, args)
+	case filters.OrExpression:
+		return s.renderOperands(e, " OR ", "FALSE", columns, args)
+	case filters.NotExpression:
+		operand, ok := s.renderExpression(e.Operand, columns, args)
+		if !ok {
+			return "", false
+		}
+
+		// a comparison with NULL is neither true nor false, while Check
+		// treats it as false, so NOT has to see it as false too
+		return "NOT COALESCE(" + operand + ", FALSE)", true
+	default:
+		return "", false
+	}
+}
+
+func (s *DataStore) renderOperands(operands []filters.Expression, separator string, empty string, columns map[string]string, args *[]any) (string, bool) {
+	if len(operands) == 0 {
+		return empty, true
+	}
+
+	clauses := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		clause, ok := s.renderExpression(operand, columns, args)
+		if !ok {
+			return "", false
+		}
+
+		if len(operands) > 1 && isCompound(operand) {
+			clause = "(" + clause + ")"
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	return strings.Join(clauses, separator), true
+}
+
+// isCompound reports whether an expression renders to several operands joined
+// by AND or OR, which need parentheses inside another AND or OR.
+func isCompound(expression filters.Expression) bool {
+	switch e := expression.(type) {
+	case filters.AndExpression:
+		if len(e) == 1 {
+			return isCompound(e[0])
+		}
+		return len(e) > 1
+	case filters.OrExpression:
+		if len(e) == 1 {
+			return isCompound(e[0])
+		}
+		return len(e) > 1
+	default:
+		return false
+	}
+}
+
+func (s *DataStore) renderCondition(condition filters.Condition, columns map[string]string, args *[]any) (string, bool) {
+	column, ok := columns[condition.Field]
+	if !ok {
+		return "", false
+	}
+
+	switch condition.Operator {
+	case filters.OperatorEqual, filters.OperatorGreaterThan, filters.OperatorLessThan:
+		*args = append(*args, s.sqlValue(condition.Value))
+		return fmt.Sprintf("%s %s ?", column, condition.Operator), true
+	case filters.OperatorIn:
+		values, ok := condition.Value.([]any)
+		if !ok {
+			return "", false
+		}
+
+		if len(values) == 0 {
+			return "FALSE", true
+		}
+
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			*args = append(*args, s.sqlValue(value))
+			placeholders = append(placeholders, "?")
+		}
+
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), true
+	default:
+		return "", false
+	}
+}
+
+func (s *DataStore) sqlValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return s.dialect.Time(v)
+	case bool:
+		return v
+	}
+
+	// string-based enums such as types.CommandStatus
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.String {
+		return rv.String()
+	}
+
+	return value
+}
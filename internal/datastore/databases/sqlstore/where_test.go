@@ -0,0 +1,114 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+// testDialect stores times as nanoseconds since the epoch, like SQLite.
+type testDialect struct{}
+
+func (testDialect) Rebind(query string) string        { return query }
+func (testDialect) Time(t time.Time) any              { return t.UnixNano() }
+func (testDialect) JSON(data []byte) any              { return string(data) }
+func (testDialect) MapError(error) error              { return nil }
+func (testDialect) ForUpdate() string                 { return "" }
+func (testDialect) SkipLocked() string                { return "" }
+func (testDialect) OrderByBytes(column string) string { return column }
+func (testDialect) LockMigrations(*sql.Tx) error      { return nil }
+func (testDialect) ColumnTypes() ColumnTypes          { return ColumnTypes{} }
+
+type checkOnlyFilter struct{}
+
+func (checkOnlyFilter) Check(types.Command) bool {
+	return true
+}
+
+func TestWhereClause(t *testing.T) {
+	threshold := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+
+		inputFilter       any
+		expectedWhere     string
+		expectedArgs      []any
+		expectedTranslate bool
+	}{
+		{
+			name:              "No Conditions",
+			inputFilter:       filters.NewCommandFilter(),
+			expectedTranslate: true,
+		},
+		{
+			name: "Command Filter",
+			inputFilter: filters.NewCommandFilter().
+				ByEntityID("1").
+				ByStatus(types.CommandStatusPending).
+				ByTimeAfterIssuing(threshold),
+			expectedWhere:     " WHERE entity_id = ? AND status = ? AND issued_at > ?",
+			expectedArgs:      []any{"1", "pending", threshold.UnixNano()},
+			expectedTranslate: true,
+		},
+		{
+			name: "Combinators",
+			inputFilter: filters.Or[types.Command](
+				filters.In(func(status types.CommandStatus) datastore.Filter[types.Command] {
+					return filters.NewCommandFilter().ByStatus(status)
+				}, types.CommandStatusPending, types.CommandStatusFailure),
+				filters.And[types.Command](
+					filters.NewCommandFilter().ByEntityID("1"),
+					filters.Not[types.Command](filters.NewCommandFilter().ByEntityID("2")),
+				),
+			),
+			expectedWhere:     " WHERE status IN (?, ?) OR (entity_id = ? AND NOT COALESCE(entity_id = ?, FALSE))",
+			expectedArgs:      []any{"pending", "failure", "1", "2"},
+			expectedTranslate: true,
+		},
+		{
+			name: "Empty In",
+			inputFilter: filters.In[types.Command, string](func(entityID string) datastore.Filter[types.Command] {
+				return filters.NewCommandFilter().ByEntityID(entityID)
+			}),
+			expectedWhere:     " WHERE FALSE",
+			expectedTranslate: true,
+		},
+		{
+			name:              "Combinator Over Unknown Field",
+			inputFilter:       filters.Not[types.ReportSubscription](filters.NewReportSubscriptionFilter().ByIsActive(true)),
+			expectedTranslate: false,
+		},
+		{
+			name:              "Unknown Field",
+			inputFilter:       filters.NewReportSubscriptionFilter().ByIsActive(true),
+			expectedTranslate: false,
+		},
+		{
+			name:              "Not A Conditioner",
+			inputFilter:       checkOnlyFilter{},
+			expectedTranslate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := New(nil, testDialect{})
+
+			where, args, translated := store.whereClause(tt.inputFilter, commandFilterColumns)
+
+			if translated != tt.expectedTranslate {
+				t.Fatalf("Test failed. Expected translated: %v, Got: %v", tt.expectedTranslate, translated)
+			}
+
+			if where != tt.expectedWhere || !reflect.DeepEqual(tt.expectedArgs, args) {
+				t.Errorf("Test failed. Expected: %q %v, Got: %q %v", tt.expectedWhere, tt.expectedArgs, where, args)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"time"
+
 	"github.com/pmoura-dev/esr-service/internal/types"
 )
 
@@ -8,39 +10,101 @@ type DataStore interface {
 	Init() error
 	Close()
 
+	Migrator
+
 	EntityRepository
 	CommandRepository
 	ReportSubscriptionRepository
+	StateRepository
+	OutboxRepository
+	DeadLetterRepository
 }
 
 type EntityRepository interface {
 	GetEntityByID(id string) (types.Entity, error)
-	ListEntities() ([]types.Entity, error)
+	ListEntities(options ListOptions) (Page[types.Entity], error)
 	AddEntity(entity types.Entity) error
-	DeleteEntity(id string) error
+	DeleteEntity(id string, policy DeletionPolicy) error
 }
 
+// DeletionPolicy decides what happens to the commands and report subscriptions
+// of an entity when it is deleted. Its states, and its outbox messages not sent
+// yet, are always deleted with it.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRestrict refuses to delete an entity that is still
+	// referenced, returning a *ReferencedError.
+	DeletionPolicyRestrict DeletionPolicy = "restrict"
+	// DeletionPolicyCascade deletes every record referencing the entity.
+	DeletionPolicyCascade DeletionPolicy = "cascade"
+)
+
 type CommandRepository interface {
 	GetCommandByID(id string) (types.Command, error)
-	ListCommands(filter Filter[types.Command]) ([]types.Command, error)
-	AddCommand(command types.Command) error
-	ResolveCommand(id string, result types.CommandStatus) error
+	ListCommands(filter Filter[types.Command], options ListOptions) (Page[types.Command], error)
+	// AddCommand stores the command and, in the same transaction, the outbox
+	// messages announcing it. It returns ErrRecordNotFound when the entity of
+	// the command does not exist.
+	AddCommand(command types.Command, outbox ...types.OutboxMessage) error
+	// ResolveCommand resolves a pending command. It returns ErrAlreadyResolved,
+	// and leaves the command as is, when it is not pending anymore.
+	ResolveCommand(id string, result types.CommandStatus, reason string) error
 	DeleteCommand(id string) error
 }
 
 type ReportSubscriptionRepository interface {
 	GetReportSubscriptionByID(id int) (types.ReportSubscription, error)
-	ListReportSubscriptions(filter Filter[types.ReportSubscription]) ([]types.ReportSubscription, error)
-	AddReportSubscription(reportSubscription types.ReportSubscription) error
-	DeleteReportSubscription(id int) error
-	ActivateReportSubscription(id int) error
-	DeactivateReportSubscription(id int) error
+	ListReportSubscriptions(filter Filter[types.ReportSubscription], options ListOptions) (Page[types.ReportSubscription], error)
+	// AddReportSubscription, DeleteReportSubscription, ActivateReportSubscription
+	// and DeactivateReportSubscription change a subscription and, in the same
+	// transaction, store the outbox messages announce returns for the active
+	// subscriptions of its entity after the change. AddReportSubscription
+	// returns ErrRecordNotFound when the entity does not exist.
+	AddReportSubscription(reportSubscription types.ReportSubscription, announce ...ReportingAnnouncer) (int, error)
+	DeleteReportSubscription(id int, announce ...ReportingAnnouncer) error
+	ActivateReportSubscription(id int, announce ...ReportingAnnouncer) error
+	DeactivateReportSubscription(id int, announce ...ReportingAnnouncer) error
 }
 
+// ReportingAnnouncer returns the outbox messages announcing the active report
+// subscriptions of an entity, sorted by ID.
+type ReportingAnnouncer func(entityID string, active []types.ReportSubscription) ([]types.OutboxMessage, error)
+
 type MetricRepository interface{}
 
 type StateRepository interface {
-	GetStateByEntityID(entityID int) (types.State, error)
+	GetLatestStateByEntityID(entityID string) (types.State, error)
+	ListStates(filter Filter[types.State]) ([]types.State, error)
+	AddState(state types.State) error
+	DeleteStatesByEntityID(entityID string) error
+}
+
+// OutboxRepository gives access to the messages stored by other repositories
+// until they are published.
+type OutboxRepository interface {
+	// AddOutboxMessages stores messages that do not announce a record stored
+	// by another repository.
+	AddOutboxMessages(messages ...types.OutboxMessage) error
+	// ListPendingOutboxMessages returns up to limit unsent messages, oldest first.
+	ListPendingOutboxMessages(limit int) ([]types.OutboxMessage, error)
+	// ClaimPendingOutboxMessages claims up to limit unsent messages, oldest
+	// first, that are not claimed or whose claim expired before now. They are
+	// claimed until now plus lease, so no other relay claims them meanwhile.
+	ClaimPendingOutboxMessages(limit int, now time.Time, lease time.Duration) ([]types.OutboxMessage, error)
+	// ReleaseOutboxMessages drops the claim of unsent messages, so they can be
+	// claimed again right away.
+	ReleaseOutboxMessages(ids []int) error
+	MarkOutboxMessageSent(id int, sentAt time.Time) error
+	DeleteSentOutboxMessages(sentBefore time.Time) error
+}
+
+// DeadLetterRepository keeps the inbound messages that could not be handled.
+type DeadLetterRepository interface {
+	GetDeadLetterByID(id int) (types.DeadLetter, error)
+	ListDeadLetters(options ListOptions) (Page[types.DeadLetter], error)
+	AddDeadLetter(deadLetter types.DeadLetter) (int, error)
+	DeleteDeadLetter(id int) error
 }
 
 type Filter[T any] interface {
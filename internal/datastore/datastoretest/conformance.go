@@ -0,0 +1,1157 @@
+// Package datastoretest provides a backend-agnostic conformance suite for
+// datastore.DataStore implementations.
+package datastoretest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+// RunConformanceTests checks that the datastores returned by newDataStore
+// behave like the reference BoltDB implementation. Every call to newDataStore
+// must return a fresh, empty and not yet initialized datastore, or nil if it
+// could not be created.
+func RunConformanceTests(t *testing.T, newDataStore func() datastore.DataStore) {
+	t.Run("Migrator", func(t *testing.T) {
+		testMigrator(t, newDataStore)
+	})
+
+	t.Run("EntityRepository", func(t *testing.T) {
+		testEntityRepository(t, newDataStore)
+	})
+
+	t.Run("CommandRepository", func(t *testing.T) {
+		testCommandRepository(t, newDataStore)
+	})
+
+	t.Run("ReportSubscriptionRepository", func(t *testing.T) {
+		testReportSubscriptionRepository(t, newDataStore)
+	})
+
+	t.Run("StateRepository", func(t *testing.T) {
+		testStateRepository(t, newDataStore)
+	})
+
+	t.Run("OutboxRepository", func(t *testing.T) {
+		testOutboxRepository(t, newDataStore)
+	})
+
+	t.Run("DeadLetterRepository", func(t *testing.T) {
+		testDeadLetterRepository(t, newDataStore)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		testPagination(t, newDataStore)
+	})
+}
+
+func setupStore(t *testing.T, newDataStore func() datastore.DataStore) datastore.DataStore {
+	store := newDataStore()
+	if store == nil {
+		t.Fatal("failed to create datastore")
+	}
+	t.Cleanup(store.Close)
+
+	if err := store.Init(); err != nil {
+		t.Fatalf("failed to init datastore: %v", err)
+	}
+
+	return store
+}
+
+func testMigrator(t *testing.T, newDataStore func() datastore.DataStore) {
+	t.Run("Init applies every migration", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+
+		version, err := store.SchemaVersion()
+		expectNoError(t, err)
+
+		statusList, err := store.MigrationStatus()
+		expectNoError(t, err)
+
+		if len(statusList) == 0 {
+			t.Fatal("Test failed. Expected at least one migration")
+		}
+
+		for _, status := range statusList {
+			if !status.Applied {
+				t.Errorf("Test failed. Migration %d (%s) was not applied", status.Version, status.Description)
+			}
+		}
+
+		expectEqual(t, statusList[len(statusList)-1].Version, version)
+	})
+
+	t.Run("Init is idempotent", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		before, err := store.SchemaVersion()
+		expectNoError(t, err)
+
+		expectNoError(t, store.Init())
+
+		after, err := store.SchemaVersion()
+		expectNoError(t, err)
+		expectEqual(t, before, after)
+
+		got, err := store.GetEntityByID(mockEntity1.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockEntity1, got)
+	})
+}
+
+func expectError(t *testing.T, expected error, got error) {
+	t.Helper()
+
+	if !errors.Is(got, expected) {
+		t.Errorf("Test failed. Expected error: %v, Got: %v", expected, got)
+	}
+}
+
+func expectNoError(t *testing.T, got error) {
+	t.Helper()
+
+	if got != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", got)
+	}
+}
+
+func expectEqual[T any](t *testing.T, expected T, got T) {
+	t.Helper()
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+	}
+}
+
+// sortedBy returns a sorted copy of list, so results can be compared
+// regardless of the order a backend returns them in.
+func sortedBy[T any](list []T, key func(T) string) []T {
+	sorted := append([]T{}, list...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return key(sorted[i]) < key(sorted[j])
+	})
+
+	return sorted
+}
+
+func testEntityRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	entityKey := func(e types.Entity) string { return e.ID }
+
+	t.Run("GetEntityByID", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		got, err := store.GetEntityByID(mockEntity1.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockEntity1, got)
+
+		_, err = store.GetEntityByID("missing")
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ListEntities", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+
+		page, err := store.ListEntities(datastore.ListOptions{})
+		expectNoError(t, err)
+		if len(page.Items) != 0 {
+			t.Errorf("Test failed. Expected no entities, Got: %+v", page.Items)
+		}
+
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddEntity(mockEntity2))
+
+		page, err = store.ListEntities(datastore.ListOptions{})
+		expectNoError(t, err)
+		expectEqual(t, []types.Entity{mockEntity1, mockEntity2}, sortedBy(page.Items, entityKey))
+	})
+
+	t.Run("AddEntity", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		err := store.AddEntity(types.Entity{ID: mockEntity1.ID, Name: "Duplicate"})
+		expectError(t, datastore.ErrDuplicateRecord, err)
+
+		got, err := store.GetEntityByID(mockEntity1.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockEntity1, got)
+	})
+
+	t.Run("DeleteEntity", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		expectNoError(t, store.DeleteEntity(mockEntity1.ID, datastore.DeletionPolicyRestrict))
+
+		_, err := store.GetEntityByID(mockEntity1.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		err = store.DeleteEntity(mockEntity1.ID, datastore.DeletionPolicyRestrict)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	seedReferences := func(t *testing.T) (datastore.DataStore, int) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddEntity(mockEntity2))
+		expectNoError(t, store.AddCommand(mockCommand1Pending, mockOutboxMessage1))
+		expectNoError(t, store.AddCommand(mockCommand1Success))
+		expectNoError(t, store.AddCommand(mockCommand2Failed, mockOutboxMessage3))
+		expectNoError(t, store.AddState(mockState1Off))
+
+		subscription := mockReportSubscription1State
+		subscription.EntityID = mockEntity1.ID
+
+		id, err := store.AddReportSubscription(subscription)
+		expectNoError(t, err)
+
+		return store, id
+	}
+
+	t.Run("DeleteEntity - Restrict", func(t *testing.T) {
+		store, subscriptionID := seedReferences(t)
+
+		err := store.DeleteEntity(mockEntity1.ID, datastore.DeletionPolicyRestrict)
+		expectError(t, datastore.ErrRecordReferenced, err)
+
+		var referencedErr *datastore.ReferencedError
+		if !errors.As(err, &referencedErr) {
+			t.Fatalf("Test failed. Expected a *datastore.ReferencedError, Got: %v", err)
+		}
+
+		expectEqual(t, types.EntityReferences{
+			Commands:            []string{mockCommand1Pending.ID, mockCommand1Success.ID},
+			ReportSubscriptions: []int{subscriptionID},
+		}, referencedErr.References)
+
+		// nothing was deleted
+		_, err = store.GetEntityByID(mockEntity1.ID)
+		expectNoError(t, err)
+
+		_, err = store.GetLatestStateByEntityID(mockEntity1.ID)
+		expectNoError(t, err)
+	})
+
+	t.Run("DeleteEntity - Cascade", func(t *testing.T) {
+		store, subscriptionID := seedReferences(t)
+
+		expectNoError(t, store.DeleteEntity(mockEntity1.ID, datastore.DeletionPolicyCascade))
+
+		_, err := store.GetEntityByID(mockEntity1.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		_, err = store.GetCommandByID(mockCommand1Pending.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		_, err = store.GetReportSubscriptionByID(subscriptionID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		_, err = store.GetLatestStateByEntityID(mockEntity1.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		// records of other entities are left alone
+		got, err := store.GetCommandByID(mockCommand2Failed.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockCommand2Failed, got)
+
+		// so the commands of the entity are not published anymore
+		pending, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+
+		if len(pending) != 1 || pending[0].MessageID != mockOutboxMessage3.MessageID {
+			t.Errorf("Test failed. Expected: %+v, Got: %+v", []types.OutboxMessage{mockOutboxMessage3}, pending)
+		}
+	})
+}
+
+func testCommandRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	commandKey := func(c types.Command) string { return c.ID }
+
+	seed := func(t *testing.T) datastore.DataStore {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddEntity(mockEntity2))
+		for _, command := range []types.Command{mockCommand1Pending, mockCommand1Success, mockCommand2Failed, mockCommand2PendingTimeout} {
+			expectNoError(t, store.AddCommand(command))
+		}
+
+		return store
+	}
+
+	t.Run("GetCommandByID", func(t *testing.T) {
+		store := seed(t)
+
+		got, err := store.GetCommandByID(mockCommand1Success.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockCommand1Success, got)
+
+		_, err = store.GetCommandByID("missing")
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ListCommands", func(t *testing.T) {
+		tests := []struct {
+			name string
+
+			inputFilter datastore.Filter[types.Command]
+			expected    []types.Command
+		}{
+			{
+				name:        "No filter",
+				inputFilter: filters.NewCommandFilter(),
+				expected:    []types.Command{mockCommand1Pending, mockCommand1Success, mockCommand2Failed, mockCommand2PendingTimeout},
+			},
+			{
+				name:        "Filter by: EntityID",
+				inputFilter: filters.NewCommandFilter().ByEntityID("1"),
+				expected:    []types.Command{mockCommand1Pending, mockCommand1Success},
+			},
+			{
+				name:        "Filter by: Status",
+				inputFilter: filters.NewCommandFilter().ByStatus(types.CommandStatusPending),
+				expected:    []types.Command{mockCommand1Pending, mockCommand2PendingTimeout},
+			},
+			{
+				name:        "Filter by: Time After Issuing",
+				inputFilter: filters.NewCommandFilter().ByTimeAfterIssuing(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected:    []types.Command{mockCommand1Success, mockCommand2Failed, mockCommand2PendingTimeout},
+			},
+			{
+				name:        "Filter by: Time Before Issuing",
+				inputFilter: filters.NewCommandFilter().ByTimeBeforeIssuing(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected:    []types.Command{mockCommand1Pending},
+			},
+			{
+				name:        "Filter by: Time Before Timeout",
+				inputFilter: filters.NewCommandFilter().ByTimeBeforeTimeout(time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected:    []types.Command{mockCommand2PendingTimeout},
+			},
+			{
+				name: "Filter by: EntityID and Status",
+				inputFilter: filters.NewCommandFilter().
+					ByEntityID("2").
+					ByStatus(types.CommandStatusFailure),
+				expected: []types.Command{mockCommand2Failed},
+			},
+			{
+				name:        "Filter by: No Match",
+				inputFilter: filters.NewCommandFilter().ByEntityID("missing"),
+				expected:    []types.Command{},
+			},
+			{
+				name: "Filter by: Or",
+				inputFilter: filters.Or[types.Command](
+					filters.NewCommandFilter().ByStatus(types.CommandStatusSuccess),
+					filters.NewCommandFilter().ByEntityID("2").ByStatus(types.CommandStatusFailure),
+				),
+				expected: []types.Command{mockCommand1Success, mockCommand2Failed},
+			},
+			{
+				name: "Filter by: In",
+				inputFilter: filters.In(func(s types.CommandStatus) datastore.Filter[types.Command] {
+					return filters.NewCommandFilter().ByStatus(s)
+				}, types.CommandStatusSuccess, types.CommandStatusFailure),
+				expected: []types.Command{mockCommand1Success, mockCommand2Failed},
+			},
+			{
+				// commands without a timeout never match the inner filter, so they match its negation
+				name:        "Filter by: Not",
+				inputFilter: filters.Not[types.Command](filters.NewCommandFilter().ByTimeBeforeTimeout(time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC))),
+				expected:    []types.Command{mockCommand1Pending, mockCommand1Success, mockCommand2Failed},
+			},
+			{
+				name: "Filter by: Nested",
+				inputFilter: filters.And[types.Command](
+					filters.NewCommandFilter().ByStatus(types.CommandStatusPending),
+					filters.Not[types.Command](filters.Or[types.Command](
+						filters.NewCommandFilter().ByEntityID("2"),
+						filters.NewCommandFilter().ByTimeAfterIssuing(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+					)),
+				),
+				expected: []types.Command{mockCommand1Pending},
+			},
+			{
+				name:        "Filter by: Empty Or",
+				inputFilter: filters.Or[types.Command](),
+				expected:    []types.Command{},
+			},
+		}
+
+		store := seed(t)
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := store.ListCommands(tt.inputFilter, datastore.ListOptions{})
+				expectNoError(t, err)
+				expectEqual(t, tt.expected, sortedBy(page.Items, commandKey))
+			})
+		}
+	})
+
+	t.Run("AddCommand", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+
+		err := store.AddCommand(mockCommand1Pending)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddCommand(mockCommand1Pending))
+
+		got, err := store.GetCommandByID(mockCommand1Pending.ID)
+		expectNoError(t, err)
+		expectEqual(t, mockCommand1Pending, got)
+	})
+
+	t.Run("ResolveCommand", func(t *testing.T) {
+		store := seed(t)
+
+		expectNoError(t, store.ResolveCommand(mockCommand2PendingTimeout.ID, types.CommandStatusFailure, "command timed out"))
+
+		got, err := store.GetCommandByID(mockCommand2PendingTimeout.ID)
+		expectNoError(t, err)
+
+		if got.Status != types.CommandStatusFailure || got.Reason != "command timed out" || got.ResolvedAt == nil {
+			t.Errorf("Test failed. Command was not resolved: %+v", got)
+		}
+
+		err = store.ResolveCommand("missing", types.CommandStatusSuccess, "")
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ResolveCommand - Already Resolved", func(t *testing.T) {
+		store := seed(t)
+
+		expectNoError(t, store.ResolveCommand(mockCommand2PendingTimeout.ID, types.CommandStatusSuccess, ""))
+
+		err := store.ResolveCommand(mockCommand2PendingTimeout.ID, types.CommandStatusFailure, "command timed out")
+		expectError(t, datastore.ErrAlreadyResolved, err)
+
+		got, err := store.GetCommandByID(mockCommand2PendingTimeout.ID)
+		expectNoError(t, err)
+
+		if got.Status != types.CommandStatusSuccess || got.Reason != "" {
+			t.Errorf("Test failed. Command was resolved again: %+v", got)
+		}
+	})
+
+	t.Run("DeleteCommand", func(t *testing.T) {
+		store := seed(t)
+
+		expectNoError(t, store.DeleteCommand(mockCommand1Pending.ID))
+
+		_, err := store.GetCommandByID(mockCommand1Pending.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		err = store.DeleteCommand(mockCommand1Pending.ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+}
+
+func testReportSubscriptionRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	subscriptionKey := func(s types.ReportSubscription) string { return s.EntityID + "/" + string(s.ReportType) }
+
+	seed := func(t *testing.T) (datastore.DataStore, map[string]types.ReportSubscription) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddEntity(mockEntity2))
+
+		seeded := map[string]types.ReportSubscription{}
+		for _, subscription := range []types.ReportSubscription{mockReportSubscription1State, mockReportSubscription1MetricPower, mockReportSubscription2State} {
+			id, err := store.AddReportSubscription(subscription)
+			expectNoError(t, err)
+
+			subscription.ID = id
+			seeded[subscriptionKey(subscription)] = subscription
+		}
+
+		return store, seeded
+	}
+
+	t.Run("GetReportSubscriptionByID", func(t *testing.T) {
+		store, seeded := seed(t)
+		expected := seeded[subscriptionKey(mockReportSubscription1MetricPower)]
+
+		got, err := store.GetReportSubscriptionByID(expected.ID)
+		expectNoError(t, err)
+		expectEqual(t, expected, got)
+
+		_, err = store.GetReportSubscriptionByID(-1)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ListReportSubscriptions", func(t *testing.T) {
+		store, seeded := seed(t)
+		state1 := seeded[subscriptionKey(mockReportSubscription1State)]
+		metric1 := seeded[subscriptionKey(mockReportSubscription1MetricPower)]
+		state2 := seeded[subscriptionKey(mockReportSubscription2State)]
+
+		tests := []struct {
+			name string
+
+			inputFilter datastore.Filter[types.ReportSubscription]
+			expected    []types.ReportSubscription
+		}{
+			{
+				name:        "No filter",
+				inputFilter: filters.NewReportSubscriptionFilter(),
+				expected:    []types.ReportSubscription{metric1, state1, state2},
+			},
+			{
+				name:        "Filter by: EntityID",
+				inputFilter: filters.NewReportSubscriptionFilter().ByEntityID(mockEntity1.ID),
+				expected:    []types.ReportSubscription{metric1, state1},
+			},
+			{
+				name:        "Filter by: ReportType",
+				inputFilter: filters.NewReportSubscriptionFilter().ByReportType(types.ReportTypeState),
+				expected:    []types.ReportSubscription{state1, state2},
+			},
+			{
+				name:        "Filter by: Is Active",
+				inputFilter: filters.NewReportSubscriptionFilter().ByIsActive(false),
+				expected:    []types.ReportSubscription{metric1},
+			},
+			{
+				name:        "Filter by: Time After Update",
+				inputFilter: filters.NewReportSubscriptionFilter().ByTimeAfterUpdated(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected:    []types.ReportSubscription{state2},
+			},
+			{
+				name:        "Filter by: Time Before Update",
+				inputFilter: filters.NewReportSubscriptionFilter().ByTimeBeforeUpdated(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected:    []types.ReportSubscription{metric1, state1},
+			},
+			{
+				name:        "Filter by: No Match",
+				inputFilter: filters.NewReportSubscriptionFilter().ByEntityID("missing"),
+				expected:    []types.ReportSubscription{},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := store.ListReportSubscriptions(tt.inputFilter, datastore.ListOptions{})
+				expectNoError(t, err)
+				expectEqual(t, tt.expected, sortedBy(page.Items, subscriptionKey))
+			})
+		}
+	})
+
+	t.Run("AddReportSubscription", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+
+		_, err := store.AddReportSubscription(mockReportSubscription1State)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		firstID, err := store.AddReportSubscription(mockReportSubscription1State)
+		expectNoError(t, err)
+
+		secondID, err := store.AddReportSubscription(mockReportSubscription1State)
+		expectNoError(t, err)
+
+		if firstID == secondID {
+			t.Errorf("Test failed. Expected distinct IDs, Got: %d and %d", firstID, secondID)
+		}
+
+		got, err := store.GetReportSubscriptionByID(secondID)
+		expectNoError(t, err)
+
+		expected := mockReportSubscription1State
+		expected.ID = secondID
+		expectEqual(t, expected, got)
+	})
+
+	t.Run("DeleteReportSubscription", func(t *testing.T) {
+		store, seeded := seed(t)
+		id := seeded[subscriptionKey(mockReportSubscription1State)].ID
+
+		expectNoError(t, store.DeleteReportSubscription(id))
+
+		_, err := store.GetReportSubscriptionByID(id)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		err = store.DeleteReportSubscription(id)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ActivateReportSubscription", func(t *testing.T) {
+		store, seeded := seed(t)
+		id := seeded[subscriptionKey(mockReportSubscription1MetricPower)].ID
+
+		expectNoError(t, store.ActivateReportSubscription(id))
+
+		got, err := store.GetReportSubscriptionByID(id)
+		expectNoError(t, err)
+
+		if !got.IsActive {
+			t.Errorf("Test failed. Subscription was not activated: %+v", got)
+		}
+
+		err = store.ActivateReportSubscription(-1)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("DeactivateReportSubscription", func(t *testing.T) {
+		store, seeded := seed(t)
+		id := seeded[subscriptionKey(mockReportSubscription1State)].ID
+
+		expectNoError(t, store.DeactivateReportSubscription(id))
+
+		got, err := store.GetReportSubscriptionByID(id)
+		expectNoError(t, err)
+
+		if got.IsActive {
+			t.Errorf("Test failed. Subscription was not deactivated: %+v", got)
+		}
+
+		err = store.DeactivateReportSubscription(-1)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("Announce", func(t *testing.T) {
+		store, seeded := seed(t)
+		state1 := seeded[subscriptionKey(mockReportSubscription1State)]
+		metric1 := seeded[subscriptionKey(mockReportSubscription1MetricPower)]
+
+		// announced holds the IDs of the active subscriptions of each call
+		var announced [][]int
+		announce := func(entityID string, active []types.ReportSubscription) ([]types.OutboxMessage, error) {
+			ids := []int{}
+			for _, subscription := range active {
+				ids = append(ids, subscription.ID)
+			}
+			announced = append(announced, ids)
+
+			return []types.OutboxMessage{{
+				MessageID: fmt.Sprintf("reporting-%d", len(announced)),
+				EntityID:  entityID,
+				Topic:     "entities/" + entityID + "/reporting",
+				Payload:   []byte(`{}`),
+				CreatedAt: time.Date(2012, 11, 10, 23, 0, 0, 0, time.UTC),
+			}}, nil
+		}
+
+		expectNoError(t, store.ActivateReportSubscription(metric1.ID, announce))
+		expectNoError(t, store.DeactivateReportSubscription(state1.ID, announce))
+		expectNoError(t, store.DeleteReportSubscription(metric1.ID, announce))
+
+		id, err := store.AddReportSubscription(mockReportSubscription1State, announce)
+		expectNoError(t, err)
+
+		expectEqual(t, [][]int{{state1.ID, metric1.ID}, {metric1.ID}, {}, {id}}, announced)
+
+		pending, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+
+		if len(pending) != 4 || pending[0].EntityID != mockEntity1.ID {
+			t.Errorf("Test failed. Expected four messages of entity 1, Got: %+v", pending)
+		}
+
+		// a failed announcement leaves the subscriptions as they are
+		errAnnounce := errors.New("announce failed")
+		fail := func(string, []types.ReportSubscription) ([]types.OutboxMessage, error) {
+			return nil, errAnnounce
+		}
+
+		expectError(t, errAnnounce, store.ActivateReportSubscription(state1.ID, fail))
+		expectError(t, errAnnounce, store.DeleteReportSubscription(id, fail))
+
+		_, err = store.AddReportSubscription(mockReportSubscription2State, fail)
+		expectError(t, errAnnounce, err)
+
+		got, err := store.ListReportSubscriptions(filters.NewReportSubscriptionFilter().ByIsActive(true), datastore.ListOptions{})
+		expectNoError(t, err)
+
+		ids := []int{}
+		for _, subscription := range got.Items {
+			ids = append(ids, subscription.ID)
+		}
+		expectEqual(t, []int{seeded[subscriptionKey(mockReportSubscription2State)].ID, id}, ids)
+
+		pending, err = store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+
+		if len(pending) != 4 {
+			t.Errorf("Test failed. Expected: %d messages, Got: %+v", 4, pending)
+		}
+	})
+}
+
+func testStateRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	stateKey := func(s types.State) string { return s.ReportedAt.Format(time.RFC3339) }
+
+	seed := func(t *testing.T) datastore.DataStore {
+		store := setupStore(t, newDataStore)
+		for _, state := range []types.State{mockState1On, mockState1Off, mockState2On} {
+			expectNoError(t, store.AddState(state))
+		}
+
+		return store
+	}
+
+	withoutID := func(states []types.State) []types.State {
+		list := make([]types.State, 0, len(states))
+		for _, state := range states {
+			state.ID = 0
+			list = append(list, state)
+		}
+
+		return list
+	}
+
+	t.Run("GetLatestStateByEntityID", func(t *testing.T) {
+		store := seed(t)
+
+		got, err := store.GetLatestStateByEntityID("1")
+		expectNoError(t, err)
+
+		got.ID = 0
+		expectEqual(t, mockState1On, got)
+
+		_, err = store.GetLatestStateByEntityID("missing")
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ListStates", func(t *testing.T) {
+		tests := []struct {
+			name string
+
+			inputFilter datastore.Filter[types.State]
+			expected    []types.State
+		}{
+			{
+				name:        "No filter",
+				inputFilter: filters.NewStateFilter(),
+				expected:    []types.State{mockState1Off, mockState1On, mockState2On},
+			},
+			{
+				name:        "Filter by: EntityID",
+				inputFilter: filters.NewStateFilter().ByEntityID("1"),
+				expected:    []types.State{mockState1Off, mockState1On},
+			},
+			{
+				name: "Filter by: Time Range",
+				inputFilter: filters.NewStateFilter().
+					ByTimeAfterReporting(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)).
+					ByTimeBeforeReporting(time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)),
+				expected: []types.State{mockState1On},
+			},
+		}
+
+		store := seed(t)
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := store.ListStates(tt.inputFilter)
+				expectNoError(t, err)
+				expectEqual(t, tt.expected, withoutID(sortedBy(got, stateKey)))
+			})
+		}
+	})
+
+	t.Run("AddState", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddState(mockState1Off))
+		expectNoError(t, store.AddState(mockState1Off))
+
+		got, err := store.ListStates(filters.NewStateFilter())
+		expectNoError(t, err)
+
+		if len(got) != 2 || got[0].ID == got[1].ID {
+			t.Errorf("Test failed. Expected two states with distinct IDs, Got: %+v", got)
+		}
+	})
+
+	t.Run("DeleteStatesByEntityID", func(t *testing.T) {
+		store := seed(t)
+
+		expectNoError(t, store.DeleteStatesByEntityID("1"))
+
+		got, err := store.ListStates(filters.NewStateFilter())
+		expectNoError(t, err)
+		expectEqual(t, []types.State{mockState2On}, withoutID(got))
+	})
+}
+
+func testOutboxRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	seed := func(t *testing.T) (datastore.DataStore, []types.OutboxMessage) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddCommand(mockCommand1Pending, mockOutboxMessage1, mockOutboxMessage2))
+
+		pending, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+
+		if len(pending) != 2 || pending[0].ID >= pending[1].ID {
+			t.Fatalf("Test failed. Expected two messages in ascending ID order, Got: %+v", pending)
+		}
+
+		return store, pending
+	}
+
+	withoutID := func(messages []types.OutboxMessage) []types.OutboxMessage {
+		list := make([]types.OutboxMessage, 0, len(messages))
+		for _, message := range messages {
+			message.ID = 0
+			list = append(list, message)
+		}
+
+		return list
+	}
+
+	t.Run("AddCommand", func(t *testing.T) {
+		store, pending := seed(t)
+		expectEqual(t, []types.OutboxMessage{mockOutboxMessage1, mockOutboxMessage2}, withoutID(pending))
+
+		_, err := store.GetCommandByID(mockCommand1Pending.ID)
+		expectNoError(t, err)
+	})
+
+	t.Run("AddOutboxMessages", func(t *testing.T) {
+		store, pending := seed(t)
+
+		expectNoError(t, store.AddOutboxMessages(mockOutboxMessage3))
+
+		got, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+		expectEqual(t, []types.OutboxMessage{mockOutboxMessage1, mockOutboxMessage2, mockOutboxMessage3}, withoutID(got))
+
+		if got[2].ID <= pending[1].ID {
+			t.Errorf("Test failed. Expected an ID after %d, Got: %d", pending[1].ID, got[2].ID)
+		}
+	})
+
+	t.Run("ListPendingOutboxMessages", func(t *testing.T) {
+		store, pending := seed(t)
+
+		got, err := store.ListPendingOutboxMessages(1)
+		expectNoError(t, err)
+		expectEqual(t, pending[:1], got)
+	})
+
+	t.Run("ClaimPendingOutboxMessages", func(t *testing.T) {
+		store, pending := seed(t)
+
+		now := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+		claimedUntil := now.Add(time.Minute)
+
+		claimed := make([]types.OutboxMessage, 0, len(pending))
+		for _, message := range pending {
+			message.ClaimedUntil = &claimedUntil
+			claimed = append(claimed, message)
+		}
+
+		got, err := store.ClaimPendingOutboxMessages(1, now, time.Minute)
+		expectNoError(t, err)
+		expectEqual(t, claimed[:1], got)
+
+		// claimed messages are skipped until their claim expires
+		got, err = store.ClaimPendingOutboxMessages(0, now, time.Minute)
+		expectNoError(t, err)
+		expectEqual(t, claimed[1:], got)
+
+		got, err = store.ClaimPendingOutboxMessages(0, claimedUntil, time.Minute)
+		expectNoError(t, err)
+		expectEqual(t, []types.OutboxMessage(nil), got)
+
+		got, err = store.ClaimPendingOutboxMessages(0, claimedUntil.Add(time.Second), time.Hour)
+		expectNoError(t, err)
+		expectEqual(t, 2, len(got))
+
+		// sent messages are never claimed
+		expectNoError(t, store.MarkOutboxMessageSent(pending[0].ID, now))
+
+		got, err = store.ClaimPendingOutboxMessages(0, now.Add(24*time.Hour), time.Minute)
+		expectNoError(t, err)
+		expectEqual(t, 1, len(got))
+		expectEqual(t, pending[1].ID, got[0].ID)
+	})
+
+	t.Run("ReleaseOutboxMessages", func(t *testing.T) {
+		store, pending := seed(t)
+
+		now := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := store.ClaimPendingOutboxMessages(0, now, time.Minute)
+		expectNoError(t, err)
+
+		expectNoError(t, store.ReleaseOutboxMessages([]int{pending[1].ID}))
+
+		got, err := store.ClaimPendingOutboxMessages(0, now, time.Minute)
+		expectNoError(t, err)
+		expectEqual(t, 1, len(got))
+		expectEqual(t, pending[1].ID, got[0].ID)
+
+		got, err = store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+
+		for _, message := range got {
+			if message.ClaimedUntil == nil {
+				t.Errorf("Test failed. Expected claimed messages, Got: %+v", got)
+			}
+		}
+	})
+
+	t.Run("MarkOutboxMessageSent", func(t *testing.T) {
+		store, pending := seed(t)
+
+		expectNoError(t, store.MarkOutboxMessageSent(pending[0].ID, time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		got, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+		expectEqual(t, pending[1:], got)
+
+		err = store.MarkOutboxMessageSent(pending[1].ID+1, time.Now())
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("DeleteSentOutboxMessages", func(t *testing.T) {
+		store, pending := seed(t)
+
+		expectNoError(t, store.MarkOutboxMessageSent(pending[0].ID, time.Date(2011, 1, 1, 0, 0, 0, 0, time.UTC)))
+		expectNoError(t, store.DeleteSentOutboxMessages(time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+		err := store.MarkOutboxMessageSent(pending[0].ID, time.Now())
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		got, err := store.ListPendingOutboxMessages(0)
+		expectNoError(t, err)
+		expectEqual(t, pending[1:], got)
+	})
+}
+
+func testDeadLetterRepository(t *testing.T, newDataStore func() datastore.DataStore) {
+	seed := func(t *testing.T) (datastore.DataStore, []types.DeadLetter) {
+		store := setupStore(t, newDataStore)
+
+		var seeded []types.DeadLetter
+		for _, deadLetter := range []types.DeadLetter{mockDeadLetter1, mockDeadLetter2} {
+			id, err := store.AddDeadLetter(deadLetter)
+			expectNoError(t, err)
+
+			deadLetter.ID = id
+			seeded = append(seeded, deadLetter)
+		}
+
+		return store, seeded
+	}
+
+	t.Run("GetDeadLetterByID", func(t *testing.T) {
+		store, seeded := seed(t)
+
+		got, err := store.GetDeadLetterByID(seeded[1].ID)
+		expectNoError(t, err)
+		expectEqual(t, seeded[1], got)
+
+		_, err = store.GetDeadLetterByID(-1)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+
+	t.Run("ListDeadLetters", func(t *testing.T) {
+		store, seeded := seed(t)
+
+		got := listAll(t, datastore.ListOptions{Limit: 1}, store.ListDeadLetters)
+		expectEqual(t, [][]types.DeadLetter{{seeded[0]}, {seeded[1]}}, got)
+
+		got = listAll(t, datastore.ListOptions{Sort: datastore.Sort{Field: datastore.SortByCreatedAt, Descending: true}}, store.ListDeadLetters)
+		expectEqual(t, [][]types.DeadLetter{{seeded[1], seeded[0]}}, got)
+	})
+
+	t.Run("AddDeadLetter", func(t *testing.T) {
+		_, seeded := seed(t)
+
+		if seeded[0].ID >= seeded[1].ID {
+			t.Errorf("Test failed. Expected ascending IDs, Got: %d and %d", seeded[0].ID, seeded[1].ID)
+		}
+	})
+
+	t.Run("DeleteDeadLetter", func(t *testing.T) {
+		store, seeded := seed(t)
+
+		expectNoError(t, store.DeleteDeadLetter(seeded[0].ID))
+
+		_, err := store.GetDeadLetterByID(seeded[0].ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+
+		err = store.DeleteDeadLetter(seeded[0].ID)
+		expectError(t, datastore.ErrRecordNotFound, err)
+	})
+}
+
+// listAll follows NextCursor until the last page and returns every page.
+func listAll[T any](t *testing.T, options datastore.ListOptions, list func(datastore.ListOptions) (datastore.Page[T], error)) [][]T {
+	t.Helper()
+
+	var pages [][]T
+	for {
+		page, err := list(options)
+		expectNoError(t, err)
+
+		pages = append(pages, page.Items)
+		if page.NextCursor == "" {
+			return pages
+		}
+
+		if len(pages) > 100 {
+			t.Fatal("Test failed. Pagination does not end")
+		}
+
+		options.Cursor = page.NextCursor
+	}
+}
+
+func testPagination(t *testing.T, newDataStore func() datastore.DataStore) {
+	seed := func(t *testing.T) datastore.DataStore {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+		expectNoError(t, store.AddEntity(mockEntity2))
+		for _, command := range []types.Command{mockCommand1Pending, mockCommand1Success, mockCommand2Failed, mockCommand2PendingTimeout} {
+			expectNoError(t, store.AddCommand(command))
+		}
+
+		return store
+	}
+
+	t.Run("ListCommands", func(t *testing.T) {
+		tests := []struct {
+			name string
+
+			inputFilter  datastore.Filter[types.Command]
+			inputOptions datastore.ListOptions
+			expected     [][]types.Command
+		}{
+			{
+				name:         "Sort by: ID",
+				inputFilter:  filters.NewCommandFilter(),
+				inputOptions: datastore.ListOptions{Limit: 3},
+				expected: [][]types.Command{
+					{mockCommand1Pending, mockCommand1Success, mockCommand2Failed},
+					{mockCommand2PendingTimeout},
+				},
+			},
+			{
+				name:         "Sort by: ID Descending",
+				inputFilter:  filters.NewCommandFilter(),
+				inputOptions: datastore.ListOptions{Limit: 2, Sort: datastore.Sort{Field: datastore.SortByID, Descending: true}},
+				expected: [][]types.Command{
+					{mockCommand2PendingTimeout, mockCommand2Failed},
+					{mockCommand1Success, mockCommand1Pending},
+				},
+			},
+			{
+				name:         "Sort by: Issued At Descending",
+				inputFilter:  filters.NewCommandFilter(),
+				inputOptions: datastore.ListOptions{Limit: 3, Sort: datastore.Sort{Field: datastore.SortByIssuedAt, Descending: true}},
+				expected: [][]types.Command{
+					{mockCommand2PendingTimeout, mockCommand2Failed, mockCommand1Success},
+					{mockCommand1Pending},
+				},
+			},
+			{
+				name:         "Filter by: EntityID",
+				inputFilter:  filters.NewCommandFilter().ByEntityID("2"),
+				inputOptions: datastore.ListOptions{Limit: 1},
+				expected: [][]types.Command{
+					{mockCommand2Failed},
+					{mockCommand2PendingTimeout},
+				},
+			},
+			{
+				name:         "No limit",
+				inputFilter:  filters.NewCommandFilter().ByStatus(types.CommandStatusPending),
+				inputOptions: datastore.ListOptions{Sort: datastore.Sort{Field: datastore.SortByIssuedAt}},
+				expected: [][]types.Command{
+					{mockCommand1Pending, mockCommand2PendingTimeout},
+				},
+			},
+		}
+
+		store := seed(t)
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := listAll(t, tt.inputOptions, func(options datastore.ListOptions) (datastore.Page[types.Command], error) {
+					return store.ListCommands(tt.inputFilter, options)
+				})
+				expectEqual(t, tt.expected, got)
+			})
+		}
+	})
+
+	t.Run("ListEntities", func(t *testing.T) {
+		store := seed(t)
+
+		got := listAll(t, datastore.ListOptions{Limit: 1, Sort: datastore.Sort{Field: datastore.SortByName, Descending: true}}, store.ListEntities)
+		expectEqual(t, [][]types.Entity{{mockEntity2}, {mockEntity1}}, got)
+	})
+
+	t.Run("ListReportSubscriptions", func(t *testing.T) {
+		store := setupStore(t, newDataStore)
+		expectNoError(t, store.AddEntity(mockEntity1))
+
+		var expected []int
+		for i := 0; i < 11; i++ {
+			subscription := mockReportSubscription1State
+			subscription.UpdatedAt = subscription.UpdatedAt.Add(-time.Duration(i) * time.Hour)
+
+			id, err := store.AddReportSubscription(subscription)
+			expectNoError(t, err)
+			expected = append(expected, id)
+		}
+
+		for _, options := range []datastore.ListOptions{
+			{Limit: 4},
+			{Limit: 4, Sort: datastore.Sort{Field: datastore.SortByUpdatedAt, Descending: true}},
+		} {
+			var got []int
+			pages := listAll(t, options, func(options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error) {
+				return store.ListReportSubscriptions(filters.NewReportSubscriptionFilter(), options)
+			})
+			for _, page := range pages {
+				for _, subscription := range page {
+					got = append(got, subscription.ID)
+				}
+			}
+
+			expectEqual(t, 3, len(pages))
+			expectEqual(t, expected, got)
+		}
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		store := seed(t)
+
+		page, err := store.ListCommands(filters.NewCommandFilter(), datastore.ListOptions{Limit: 1})
+		expectNoError(t, err)
+
+		_, err = store.ListCommands(filters.NewCommandFilter(), datastore.ListOptions{
+			Limit:  1,
+			Cursor: page.NextCursor,
+			Sort:   datastore.Sort{Field: datastore.SortByIssuedAt},
+		})
+		expectError(t, datastore.ErrInvalidCursor, err)
+
+		_, err = store.ListCommands(filters.NewCommandFilter(), datastore.ListOptions{Cursor: "invalid"})
+		expectError(t, datastore.ErrInvalidCursor, err)
+
+		_, err = store.ListCommands(filters.NewCommandFilter(), datastore.ListOptions{Sort: datastore.Sort{Field: "reason"}})
+		expectError(t, datastore.ErrInvalidSortField, err)
+
+		_, err = store.ListEntities(datastore.ListOptions{Limit: -1})
+		expectError(t, datastore.ErrInvalidLimit, err)
+	})
+}
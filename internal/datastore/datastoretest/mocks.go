@@ -0,0 +1,145 @@
+package datastoretest
+
+import (
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/_data"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+var (
+	mockEntity1 = types.Entity{ID: "1", Name: "TestEntity1"}
+	mockEntity2 = types.Entity{ID: "2", Name: "TestEntity2"}
+)
+
+var (
+	mockCommand1Pending = types.Command{
+		ID:           "cmd1",
+		EntityID:     "1",
+		DesiredState: map[string]any{"power": "on"},
+		Status:       types.CommandStatusPending,
+		IssuedAt:     time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockCommand1Success = types.Command{
+		ID:           "cmd2",
+		EntityID:     "1",
+		DesiredState: map[string]any{"power": "off"},
+		Status:       types.CommandStatusSuccess,
+		IssuedAt:     time.Date(2010, 11, 10, 23, 0, 0, 0, time.UTC),
+		ResolvedAt:   _data.Ptr(time.Date(2010, 11, 10, 23, 0, 10, 0, time.UTC)),
+	}
+
+	mockCommand2Failed = types.Command{
+		ID:           "cmd3",
+		EntityID:     "2",
+		DesiredState: map[string]any{"power": "off"},
+		Status:       types.CommandStatusFailure,
+		IssuedAt:     time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
+		ResolvedAt:   _data.Ptr(time.Date(2011, 11, 10, 23, 0, 10, 0, time.UTC)),
+		Reason:       "device unreachable",
+	}
+
+	mockCommand2PendingTimeout = types.Command{
+		ID:           "cmd4",
+		EntityID:     "2",
+		DesiredState: map[string]any{"power": "on"},
+		Status:       types.CommandStatusPending,
+		IssuedAt:     time.Date(2012, 11, 10, 23, 0, 0, 0, time.UTC),
+		TimeoutAt:    _data.Ptr(time.Date(2012, 11, 10, 23, 0, 30, 0, time.UTC)),
+	}
+)
+
+var (
+	mockReportSubscription1State = types.ReportSubscription{
+		EntityID:   mockEntity1.ID,
+		ReportType: types.ReportTypeState,
+		IsActive:   true,
+		UpdatedAt:  time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockReportSubscription1MetricPower = types.ReportSubscription{
+		EntityID:   mockEntity1.ID,
+		ReportType: types.ReportTypeMetric,
+		Metric:     _data.Ptr("power"),
+		UpdatedAt:  time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockReportSubscription2State = types.ReportSubscription{
+		EntityID:   mockEntity2.ID,
+		ReportType: types.ReportTypeState,
+		IsActive:   true,
+		UpdatedAt:  time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+)
+
+var (
+	mockState1Off = types.State{
+		EntityID:   "1",
+		State:      map[string]any{"power": "off"},
+		ReportedAt: time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockState1On = types.State{
+		EntityID:   "1",
+		State:      map[string]any{"power": "on"},
+		ReportedAt: time.Date(2010, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockState2On = types.State{
+		EntityID:   "2",
+		State:      map[string]any{"power": "on"},
+		ReportedAt: time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+)
+
+var (
+	mockOutboxMessage1 = types.OutboxMessage{
+		MessageID: "cmd1",
+		EntityID:  "1",
+		Topic:     "entities/1/update",
+		Payload:   []byte(`{"power":"on"}`),
+		Metadata:  map[string]string{"content_type": "application/json"},
+		CreatedAt: time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockOutboxMessage2 = types.OutboxMessage{
+		MessageID: "cmd2",
+		EntityID:  "1",
+		Topic:     "entities/1/update",
+		Payload:   []byte(`{"power":"off"}`),
+		Metadata:  map[string]string{},
+		CreatedAt: time.Date(2010, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockOutboxMessage3 = types.OutboxMessage{
+		MessageID: "cmd3",
+		EntityID:  "2",
+		Topic:     "entities/2/update",
+		Payload:   []byte(`{"power":"off"}`),
+		Metadata:  map[string]string{},
+		CreatedAt: time.Date(2011, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+)
+
+var (
+	mockDeadLetter1 = types.DeadLetter{
+		MessageID: "msg1",
+		Handler:   "report_state",
+		Topic:     "entities/*/state",
+		Payload:   []byte(`{"power":`),
+		Metadata:  map[string]string{"content_type": "application/json"},
+		Reason:    "invalid JSON payload",
+		CreatedAt: time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+
+	mockDeadLetter2 = types.DeadLetter{
+		MessageID: "msg2",
+		Handler:   "ack_command",
+		Topic:     "entities/*/commands/*/ack",
+		Payload:   []byte(`{"status":"done"}`),
+		Metadata:  map[string]string{},
+		Reason:    "payload validation failed",
+		CreatedAt: time.Date(2010, 11, 10, 23, 0, 0, 0, time.UTC),
+	}
+)
@@ -2,13 +2,35 @@ package datastore
 
 import (
 	"errors"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
 )
 
 var (
 	ErrConnectionFailed  = errors.New("datastore connection failed")
+	ErrDataStoreInUse    = errors.New("datastore is in use by another process")
 	ErrInvalidData       = errors.New("data is invalid")
 	ErrRecordNotFound    = errors.New("record was not found")
 	ErrDuplicateRecord   = errors.New("record already exists")
+	ErrRecordReferenced  = errors.New("record is still referenced")
 	ErrTableDoesNotExist = errors.New("table does not exist")
 	ErrTransactionFailed = errors.New("transaction failed")
+	ErrInvalidCursor     = errors.New("cursor is invalid")
+	ErrInvalidSortField  = errors.New("sort field is invalid")
+	ErrInvalidLimit      = errors.New("limit is invalid")
+	ErrAlreadyResolved   = errors.New("command is already resolved")
 )
+
+// ReferencedError is returned when an entity can not be deleted under
+// DeletionPolicyRestrict. It matches ErrRecordReferenced.
+type ReferencedError struct {
+	References types.EntityReferences
+}
+
+func (e *ReferencedError) Error() string {
+	return ErrRecordReferenced.Error()
+}
+
+func (e *ReferencedError) Unwrap() error {
+	return ErrRecordReferenced
+}
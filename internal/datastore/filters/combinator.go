@@ -0,0 +1,189 @@
+package filters
+
+import (
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+)
+
+// Expression is a boolean tree of conditions: a Condition, an AndExpression,
+// an OrExpression or a NotExpression. Backends translate it to evaluate a
+// filter natively.
+type Expression interface {
+	expression()
+}
+
+type AndExpression []Expression
+
+type OrExpression []Expression
+
+type NotExpression struct {
+	Operand Expression
+}
+
+func (Condition) expression()     {}
+func (AndExpression) expression() {}
+func (OrExpression) expression()  {}
+func (NotExpression) expression() {}
+
+// Expresser is implemented by filters that can be described by an expression.
+// It reports false when part of the filter can only be evaluated with Check.
+type Expresser interface {
+	Expression() (Expression, bool)
+}
+
+// ExpressionOf describes any filter as an expression, if possible.
+func ExpressionOf(filter any) (Expression, bool) {
+	switch f := filter.(type) {
+	case Expresser:
+		return f.Expression()
+	case Conditioner:
+		expression := AndExpression{}
+		for _, condition := range f.Conditions() {
+			expression = append(expression, condition)
+		}
+		return expression, true
+	default:
+		return nil, false
+	}
+}
+
+type AndFilter[T any] struct {
+	filters []datastore.Filter[T]
+}
+
+// And matches the records matched by every filter. With no filters it matches everything.
+func And[T any](filters ...datastore.Filter[T]) *AndFilter[T] {
+	return &AndFilter[T]{filters: filters}
+}
+
+func (f *AndFilter[T]) Check(item T) bool {
+	for _, filter := range f.filters {
+		if !filter.Check(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *AndFilter[T]) Expression() (Expression, bool) {
+	expression := AndExpression{}
+	for _, filter := range f.filters {
+		operand, ok := ExpressionOf(filter)
+		if !ok {
+			return nil, false
+		}
+		expression = append(expression, operand)
+	}
+
+	return expression, true
+}
+
+type OrFilter[T any] struct {
+	filters []datastore.Filter[T]
+}
+
+// Or matches the records matched by any filter. With no filters it matches nothing.
+func Or[T any](filters ...datastore.Filter[T]) *OrFilter[T] {
+	return &OrFilter[T]{filters: filters}
+}
+
+func (f *OrFilter[T]) Check(item T) bool {
+	for _, filter := range f.filters {
+		if filter.Check(item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *OrFilter[T]) Expression() (Expression, bool) {
+	expression := OrExpression{}
+	for _, filter := range f.filters {
+		operand, ok := ExpressionOf(filter)
+		if !ok {
+			return nil, false
+		}
+		expression = append(expression, operand)
+	}
+
+	return expression, true
+}
+
+type NotFilter[T any] struct {
+	filter datastore.Filter[T]
+}
+
+// Not matches the records the filter does not match.
+func Not[T any](filter datastore.Filter[T]) *NotFilter[T] {
+	return &NotFilter[T]{filter: filter}
+}
+
+func (f *NotFilter[T]) Check(item T) bool {
+	return !f.filter.Check(item)
+}
+
+func (f *NotFilter[T]) Expression() (Expression, bool) {
+	operand, ok := ExpressionOf(f.filter)
+	if !ok {
+		return nil, false
+	}
+
+	return NotExpression{Operand: operand}, true
+}
+
+type InFilter[T any] struct {
+	*OrFilter[T]
+}
+
+// In matches the records matched by the filter built for any of the values,
+// e.g. In(func(s types.CommandStatus) datastore.Filter[types.Command] {
+// return NewCommandFilter().ByStatus(s) }, pending, failure).
+func In[T any, V any](filter func(value V) datastore.Filter[T], values ...V) *InFilter[T] {
+	filters := make([]datastore.Filter[T], 0, len(values))
+	for _, value := range values {
+		filters = append(filters, filter(value))
+	}
+
+	return &InFilter[T]{OrFilter: Or(filters...)}
+}
+
+// Expression collapses equality conditions on a single field into one
+// OperatorIn condition, which backends can look up with an index.
+func (f *InFilter[T]) Expression() (Expression, bool) {
+	expression, ok := f.OrFilter.Expression()
+	if !ok {
+		return nil, false
+	}
+
+	var field string
+	var values []any
+	for _, operand := range expression.(OrExpression) {
+		condition, ok := singleCondition(operand)
+		if !ok || condition.Operator != OperatorEqual || (field != "" && condition.Field != field) {
+			return expression, true
+		}
+
+		field = condition.Field
+		values = append(values, condition.Value)
+	}
+
+	if field == "" {
+		return expression, true
+	}
+
+	return Condition{Field: field, Operator: OperatorIn, Value: values}, true
+}
+
+func singleCondition(expression Expression) (Condition, bool) {
+	switch e := expression.(type) {
+	case Condition:
+		return e, true
+	case AndExpression:
+		if len(e) == 1 {
+			return singleCondition(e[0])
+		}
+	}
+
+	return Condition{}, false
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type CommandFilter struct {
-	entityID     *string
-	status       *types.CommandStatus
-	issuedAfter  *time.Time
-	issuedBefore *time.Time
+	entityID      *string
+	status        *types.CommandStatus
+	issuedAfter   *time.Time
+	issuedBefore  *time.Time
+	timeoutBefore *time.Time
 }
 
 func NewCommandFilter() *CommandFilter {
@@ -37,6 +38,11 @@ func (f *CommandFilter) ByTimeBeforeIssuing(threshold time.Time) *CommandFilter
 	return f
 }
 
+func (f *CommandFilter) ByTimeBeforeTimeout(threshold time.Time) *CommandFilter {
+	f.timeoutBefore = &threshold
+	return f
+}
+
 func (f *CommandFilter) Check(command types.Command) bool {
 	if f.entityID != nil && *f.entityID != command.EntityID {
 		return false
@@ -54,5 +60,35 @@ func (f *CommandFilter) Check(command types.Command) bool {
 		return false
 	}
 
+	if f.timeoutBefore != nil && (command.TimeoutAt == nil || !command.TimeoutAt.Before(*f.timeoutBefore)) {
+		return false
+	}
+
 	return true
 }
+
+func (f *CommandFilter) Conditions() []Condition {
+	var conditions []Condition
+
+	if f.entityID != nil {
+		conditions = append(conditions, Condition{FieldEntityID, OperatorEqual, *f.entityID})
+	}
+
+	if f.status != nil {
+		conditions = append(conditions, Condition{FieldStatus, OperatorEqual, *f.status})
+	}
+
+	if f.issuedAfter != nil {
+		conditions = append(conditions, Condition{FieldIssuedAt, OperatorGreaterThan, *f.issuedAfter})
+	}
+
+	if f.issuedBefore != nil {
+		conditions = append(conditions, Condition{FieldIssuedAt, OperatorLessThan, *f.issuedBefore})
+	}
+
+	if f.timeoutBefore != nil {
+		conditions = append(conditions, Condition{FieldTimeoutAt, OperatorLessThan, *f.timeoutBefore})
+	}
+
+	return conditions
+}
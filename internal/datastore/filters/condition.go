@@ -0,0 +1,37 @@
+package filters
+
+const (
+	FieldEntityID   = "entity_id"
+	FieldStatus     = "status"
+	FieldIssuedAt   = "issued_at"
+	FieldTimeoutAt  = "timeout_at"
+	FieldReportType = "report_type"
+	FieldIsActive   = "is_active"
+	FieldUpdatedAt  = "updated_at"
+	FieldReportedAt = "reported_at"
+)
+
+type Operator string
+
+const (
+	OperatorEqual       Operator = "="
+	OperatorGreaterThan Operator = ">"
+	OperatorLessThan    Operator = "<"
+	OperatorIn          Operator = "IN"
+)
+
+// Condition is a single field comparison of a filter. Values are strings,
+// booleans, time.Time or string-based enums such as types.CommandStatus, or
+// a []any of those for OperatorIn.
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    any
+}
+
+// Conditioner is implemented by filters that can be expressed as a conjunction
+// of conditions, which lets backends evaluate them natively (e.g. in SQL)
+// instead of calling Check on every record.
+type Conditioner interface {
+	Conditions() []Condition
+}
@@ -0,0 +1,388 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+var ErrInvalidQuery = errors.New("invalid filter query")
+
+// QueryField builds the filter for a comparison on one field. The operator is
+// OperatorEqual, OperatorGreaterThan or OperatorLessThan.
+type QueryField[T any] func(operator Operator, value string) (datastore.Filter[T], error)
+
+type QueryFields[T any] map[string]QueryField[T]
+
+var CommandQueryFields = QueryFields[types.Command]{
+	FieldEntityID: func(operator Operator, value string) (datastore.Filter[types.Command], error) {
+		if operator != OperatorEqual {
+			return nil, unsupportedOperator(FieldEntityID, operator)
+		}
+		return NewCommandFilter().ByEntityID(value), nil
+	},
+	FieldStatus: func(operator Operator, value string) (datastore.Filter[types.Command], error) {
+		if operator != OperatorEqual {
+			return nil, unsupportedOperator(FieldStatus, operator)
+		}
+
+		status, err := types.ParseCommandStatus(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s' is invalid: %v", ErrInvalidQuery, FieldStatus, err)
+		}
+		return NewCommandFilter().ByStatus(status), nil
+	},
+	FieldIssuedAt: func(operator Operator, value string) (datastore.Filter[types.Command], error) {
+		threshold, err := parseQueryTime(FieldIssuedAt, value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch operator {
+		case OperatorGreaterThan:
+			return NewCommandFilter().ByTimeAfterIssuing(threshold), nil
+		case OperatorLessThan:
+			return NewCommandFilter().ByTimeBeforeIssuing(threshold), nil
+		default:
+			return nil, unsupportedOperator(FieldIssuedAt, operator)
+		}
+	},
+	FieldTimeoutAt: func(operator Operator, value string) (datastore.Filter[types.Command], error) {
+		threshold, err := parseQueryTime(FieldTimeoutAt, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if operator != OperatorLessThan {
+			return nil, unsupportedOperator(FieldTimeoutAt, operator)
+		}
+		return NewCommandFilter().ByTimeBeforeTimeout(threshold), nil
+	},
+}
+
+var ReportSubscriptionQueryFields = QueryFields[types.ReportSubscription]{
+	FieldEntityID: func(operator Operator, value string) (datastore.Filter[types.ReportSubscription], error) {
+		if operator != OperatorEqual {
+			return nil, unsupportedOperator(FieldEntityID, operator)
+		}
+		return NewReportSubscriptionFilter().ByEntityID(value), nil
+	},
+	FieldReportType: func(operator Operator, value string) (datastore.Filter[types.ReportSubscription], error) {
+		if operator != OperatorEqual {
+			return nil, unsupportedOperator(FieldReportType, operator)
+		}
+
+		reportType, err := types.ParseReportType(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s' is invalid: %v", ErrInvalidQuery, FieldReportType, err)
+		}
+		return NewReportSubscriptionFilter().ByReportType(reportType), nil
+	},
+	FieldIsActive: func(operator Operator, value string) (datastore.Filter[types.ReportSubscription], error) {
+		if operator != OperatorEqual {
+			return nil, unsupportedOperator(FieldIsActive, operator)
+		}
+
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s' must be a boolean", ErrInvalidQuery, FieldIsActive)
+		}
+		return NewReportSubscriptionFilter().ByIsActive(isActive), nil
+	},
+	FieldUpdatedAt: func(operator Operator, value string) (datastore.Filter[types.ReportSubscription], error) {
+		threshold, err := parseQueryTime(FieldUpdatedAt, value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch operator {
+		case OperatorGreaterThan:
+			return NewReportSubscriptionFilter().ByTimeAfterUpdated(threshold), nil
+		case OperatorLessThan:
+			return NewReportSubscriptionFilter().ByTimeBeforeUpdated(threshold), nil
+		default:
+			return nil, unsupportedOperator(FieldUpdatedAt, operator)
+		}
+	},
+}
+
+func unsupportedOperator(field string, operator Operator) error {
+	return fmt.Errorf("%w: '%s' does not support '%s'", ErrInvalidQuery, field, operator)
+}
+
+func parseQueryTime(field string, value string) (time.Time, error) {
+	threshold, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: '%s' must be an RFC3339 timestamp", ErrInvalidQuery, field)
+	}
+
+	return threshold, nil
+}
+
+// ParseQuery parses a filter query such as
+//
+//	status in (pending, failure) and not (entity_id = lamp-1 or entity_id = lamp-2)
+//
+// into a filter built from the given fields. Comparisons are field = value,
+// field != value, field > value, field < value and field in (value, ...),
+// combined with not, and, or and parentheses, in that order of precedence.
+// Values containing spaces or symbols can be double-quoted.
+func ParseQuery[T any](query string, fields QueryFields[T]) (datastore.Filter[T], error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser[T]{tokens: tokens, fields: fields}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected '%s'", ErrInvalidQuery, p.peek().text)
+	}
+
+	return filter, nil
+}
+
+type queryToken struct {
+	text string
+	// quoted tokens are always values, never keywords or symbols
+	quoted bool
+}
+
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	for i := 0; i < len(query); {
+		r := rune(query[i])
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),=<>", r):
+			tokens = append(tokens, queryToken{text: string(r)})
+			i++
+		case strings.HasPrefix(query[i:], "!="):
+			tokens = append(tokens, queryToken{text: "!="})
+			i += 2
+		case r == '"':
+			quoted, err := strconv.QuotedPrefix(query[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: unterminated quoted value", ErrInvalidQuery)
+			}
+
+			value, _ := strconv.Unquote(quoted)
+			tokens = append(tokens, queryToken{text: value, quoted: true})
+			i += len(quoted)
+		default:
+			end := i
+			for end < len(query) && !unicode.IsSpace(rune(query[end])) && !strings.ContainsRune(`(),=<>!"`, rune(query[end])) {
+				end++
+			}
+
+			if end == i {
+				return nil, fmt.Errorf("%w: unexpected '%c'", ErrInvalidQuery, r)
+			}
+
+			tokens = append(tokens, queryToken{text: query[i:end]})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type queryParser[T any] struct {
+	tokens   []queryToken
+	position int
+	fields   QueryFields[T]
+}
+
+func (p *queryParser[T]) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *queryParser[T]) peek() queryToken {
+	if p.done() {
+		return queryToken{}
+	}
+	return p.tokens[p.position]
+}
+
+func (p *queryParser[T]) next() (queryToken, error) {
+	if p.done() {
+		return queryToken{}, fmt.Errorf("%w: unexpected end of query", ErrInvalidQuery)
+	}
+
+	token := p.tokens[p.position]
+	p.position++
+	return token, nil
+}
+
+// accept consumes the next token if it is the given keyword or symbol.
+func (p *queryParser[T]) accept(text string) bool {
+	token := p.peek()
+	if p.done() || token.quoted || !strings.EqualFold(token.text, text) {
+		return false
+	}
+
+	p.position++
+	return true
+}
+
+func (p *queryParser[T]) expect(text string) error {
+	if !p.accept(text) {
+		if p.done() {
+			return fmt.Errorf("%w: expected '%s' at end of query", ErrInvalidQuery, text)
+		}
+		return fmt.Errorf("%w: expected '%s', got '%s'", ErrInvalidQuery, text, p.peek().text)
+	}
+
+	return nil
+}
+
+func (p *queryParser[T]) parseOr() (datastore.Filter[T], error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []datastore.Filter[T]{filter}
+	for p.accept("or") {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return Or(operands...), nil
+}
+
+func (p *queryParser[T]) parseAnd() (datastore.Filter[T], error) {
+	filter, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []datastore.Filter[T]{filter}
+	for p.accept("and") {
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return And(operands...), nil
+}
+
+func (p *queryParser[T]) parseNot() (datastore.Filter[T], error) {
+	if p.accept("not") {
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not(filter), nil
+	}
+
+	if p.accept("(") {
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser[T]) parseComparison() (datastore.Filter[T], error) {
+	fieldToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	field, ok := p.fields[fieldToken.text]
+	if fieldToken.quoted || !ok {
+		return nil, fmt.Errorf("%w: unknown field '%s'", ErrInvalidQuery, fieldToken.text)
+	}
+
+	switch {
+	case p.accept("="):
+		return p.parseValue(field, OperatorEqual)
+	case p.accept(">"):
+		return p.parseValue(field, OperatorGreaterThan)
+	case p.accept("<"):
+		return p.parseValue(field, OperatorLessThan)
+	case p.accept("!="):
+		filter, err := p.parseValue(field, OperatorEqual)
+		if err != nil {
+			return nil, err
+		}
+		return Not(filter), nil
+	case p.accept("in"):
+		return p.parseIn(field)
+	default:
+		return nil, fmt.Errorf("%w: expected an operator after '%s'", ErrInvalidQuery, fieldToken.text)
+	}
+}
+
+func (p *queryParser[T]) parseValue(field QueryField[T], operator Operator) (datastore.Filter[T], error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.quoted && strings.ContainsAny(token.text, "(),=<>!") {
+		return nil, fmt.Errorf("%w: expected a value, got '%s'", ErrInvalidQuery, token.text)
+	}
+
+	return field(operator, token.text)
+}
+
+func (p *queryParser[T]) parseIn(field QueryField[T]) (datastore.Filter[T], error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var operands []datastore.Filter[T]
+	for {
+		filter, err := p.parseValue(field, OperatorEqual)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, filter)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return In(func(filter datastore.Filter[T]) datastore.Filter[T] { return filter }, operands...), nil
+}
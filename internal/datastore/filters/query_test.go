@@ -0,0 +1,183 @@
+package filters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func TestParseQuery(t *testing.T) {
+	issuedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	commands := []types.Command{
+		{ID: "1", EntityID: "lamp-1", Status: types.CommandStatusPending, IssuedAt: issuedAt},
+		{ID: "2", EntityID: "lamp-2", Status: types.CommandStatusFailure, IssuedAt: issuedAt.Add(time.Hour)},
+		{ID: "3", EntityID: "lamp 3", Status: types.CommandStatusSuccess, IssuedAt: issuedAt.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+
+		inputQuery  string
+		expectedIDs []string
+		wantErr     bool
+	}{
+		{
+			name:        "Comparison",
+			inputQuery:  "status = pending",
+			expectedIDs: []string{"1"},
+		},
+		{
+			name:        "Not Equal",
+			inputQuery:  "status != pending",
+			expectedIDs: []string{"2", "3"},
+		},
+		{
+			name:        "In",
+			inputQuery:  "status in (pending, failure)",
+			expectedIDs: []string{"1", "2"},
+		},
+		{
+			name:        "Time Comparison",
+			inputQuery:  "issued_at > 2024-01-01T12:30:00Z",
+			expectedIDs: []string{"2", "3"},
+		},
+		{
+			name:        "And Binds Tighter Than Or",
+			inputQuery:  "entity_id = lamp-1 or status = failure and entity_id = lamp-1",
+			expectedIDs: []string{"1"},
+		},
+		{
+			name:        "Parentheses",
+			inputQuery:  "(entity_id = lamp-1 or status = failure) and not status = pending",
+			expectedIDs: []string{"2"},
+		},
+		{
+			name:        "Quoted Value",
+			inputQuery:  `entity_id = "lamp 3"`,
+			expectedIDs: []string{"3"},
+		},
+		{
+			name:        "Case Insensitive Keywords",
+			inputQuery:  "NOT status IN (success) AND entity_id = lamp-2",
+			expectedIDs: []string{"2"},
+		},
+		{
+			name:       "Error - Unknown Field",
+			inputQuery: "name = lamp",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Unsupported Operator",
+			inputQuery: "status > pending",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Invalid Value",
+			inputQuery: "status = done",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Missing Value",
+			inputQuery: "status =",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Unbalanced Parentheses",
+			inputQuery: "(status = pending",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Unterminated Quote",
+			inputQuery: `entity_id = "lamp`,
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Trailing Tokens",
+			inputQuery: "status = pending entity_id = lamp-1",
+			wantErr:    true,
+		},
+		{
+			name:       "Error - Empty",
+			inputQuery: "",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseQuery(tt.inputQuery, CommandQueryFields)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", ErrInvalidQuery, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			var got []string
+			for _, command := range commands {
+				if filter.Check(command) {
+					got = append(got, command.ID)
+				}
+			}
+
+			if !reflect.DeepEqual(tt.expectedIDs, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expectedIDs, got)
+			}
+		})
+	}
+}
+
+func TestInExpression(t *testing.T) {
+	byStatus := func(status types.CommandStatus) *CommandFilter {
+		return NewCommandFilter().ByStatus(status)
+	}
+
+	tests := []struct {
+		name string
+
+		inputFilter *InFilter[types.Command]
+		expected    Expression
+	}{
+		{
+			name: "Single Field",
+			inputFilter: In(func(s types.CommandStatus) datastore.Filter[types.Command] { return byStatus(s) },
+				types.CommandStatusPending, types.CommandStatusFailure),
+			expected: Condition{Field: FieldStatus, Operator: OperatorIn, Value: []any{types.CommandStatusPending, types.CommandStatusFailure}},
+		},
+		{
+			name: "Mixed Fields",
+			inputFilter: In(func(f datastore.Filter[types.Command]) datastore.Filter[types.Command] { return f },
+				datastore.Filter[types.Command](byStatus(types.CommandStatusPending)),
+				datastore.Filter[types.Command](NewCommandFilter().ByEntityID("1"))),
+			expected: OrExpression{
+				AndExpression{Condition{Field: FieldStatus, Operator: OperatorEqual, Value: types.CommandStatusPending}},
+				AndExpression{Condition{Field: FieldEntityID, Operator: OperatorEqual, Value: "1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.inputFilter.Expression()
+			if !ok {
+				t.Errorf("Test failed. Expected an expression")
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -66,3 +66,29 @@ func (f *ReportSubscriptionFilter) Check(subscription types.ReportSubscription)
 
 	return true
 }
+
+func (f *ReportSubscriptionFilter) Conditions() []Condition {
+	var conditions []Condition
+
+	if f.entityID != nil {
+		conditions = append(conditions, Condition{FieldEntityID, OperatorEqual, *f.entityID})
+	}
+
+	if f.reportType != nil {
+		conditions = append(conditions, Condition{FieldReportType, OperatorEqual, *f.reportType})
+	}
+
+	if f.isActive != nil {
+		conditions = append(conditions, Condition{FieldIsActive, OperatorEqual, *f.isActive})
+	}
+
+	if f.updatedAfter != nil {
+		conditions = append(conditions, Condition{FieldUpdatedAt, OperatorGreaterThan, *f.updatedAfter})
+	}
+
+	if f.updatedBefore != nil {
+		conditions = append(conditions, Condition{FieldUpdatedAt, OperatorLessThan, *f.updatedBefore})
+	}
+
+	return conditions
+}
@@ -0,0 +1,66 @@
+package filters
+
+import (
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+type StateFilter struct {
+	entityID       *string
+	reportedAfter  *time.Time
+	reportedBefore *time.Time
+}
+
+func NewStateFilter() *StateFilter {
+	return &StateFilter{}
+}
+
+func (f *StateFilter) ByEntityID(entityID string) *StateFilter {
+	f.entityID = &entityID
+	return f
+}
+
+func (f *StateFilter) ByTimeAfterReporting(threshold time.Time) *StateFilter {
+	f.reportedAfter = &threshold
+	return f
+}
+
+func (f *StateFilter) ByTimeBeforeReporting(threshold time.Time) *StateFilter {
+	f.reportedBefore = &threshold
+	return f
+}
+
+func (f *StateFilter) Check(state types.State) bool {
+	if f.entityID != nil && *f.entityID != state.EntityID {
+		return false
+	}
+
+	if f.reportedAfter != nil && !state.ReportedAt.After(*f.reportedAfter) {
+		return false
+	}
+
+	if f.reportedBefore != nil && !state.ReportedAt.Before(*f.reportedBefore) {
+		return false
+	}
+
+	return true
+}
+
+func (f *StateFilter) Conditions() []Condition {
+	var conditions []Condition
+
+	if f.entityID != nil {
+		conditions = append(conditions, Condition{FieldEntityID, OperatorEqual, *f.entityID})
+	}
+
+	if f.reportedAfter != nil {
+		conditions = append(conditions, Condition{FieldReportedAt, OperatorGreaterThan, *f.reportedAfter})
+	}
+
+	if f.reportedBefore != nil {
+		conditions = append(conditions, Condition{FieldReportedAt, OperatorLessThan, *f.reportedBefore})
+	}
+
+	return conditions
+}
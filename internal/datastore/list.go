@@ -0,0 +1,277 @@
+package datastore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByIssuedAt  = "issued_at"
+	SortByUpdatedAt = "updated_at"
+	SortByCreatedAt = "created_at"
+)
+
+// ListOptions sort and page the results of a List method. The zero value
+// returns every record sorted by ID.
+type ListOptions struct {
+	// Limit is the maximum number of records returned, 0 means no limit.
+	Limit int
+	// Cursor resumes a listing right after the last record of a previous page.
+	// It must be the NextCursor of a page listed with the same Sort.
+	Cursor string
+	Sort   Sort
+}
+
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+type Page[T any] struct {
+	Items []T
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// Ordering describes the fields a list of T can be sorted by. Records are
+// ordered by ID after the sort field, so the order is total and a cursor can
+// point right after any record.
+type Ordering[T any] struct {
+	// ID returns the unique key of a record.
+	ID func(T) any
+	// Fields returns, for every sortable field, the value a record is sorted
+	// by. Values are strings, ints or time.Time.
+	Fields map[string]func(T) any
+}
+
+var EntityOrdering = Ordering[types.Entity]{
+	ID: func(e types.Entity) any { return e.ID },
+	Fields: map[string]func(types.Entity) any{
+		SortByID:   func(e types.Entity) any { return e.ID },
+		SortByName: func(e types.Entity) any { return e.Name },
+	},
+}
+
+var CommandOrdering = Ordering[types.Command]{
+	ID: func(c types.Command) any { return c.ID },
+	Fields: map[string]func(types.Command) any{
+		SortByID:       func(c types.Command) any { return c.ID },
+		SortByIssuedAt: func(c types.Command) any { return c.IssuedAt },
+	},
+}
+
+var ReportSubscriptionOrdering = Ordering[types.ReportSubscription]{
+	ID: func(rs types.ReportSubscription) any { return rs.ID },
+	Fields: map[string]func(types.ReportSubscription) any{
+		SortByID:        func(rs types.ReportSubscription) any { return rs.ID },
+		SortByUpdatedAt: func(rs types.ReportSubscription) any { return rs.UpdatedAt },
+	},
+}
+
+var DeadLetterOrdering = Ordering[types.DeadLetter]{
+	ID: func(dl types.DeadLetter) any { return dl.ID },
+	Fields: map[string]func(types.DeadLetter) any{
+		SortByID:        func(dl types.DeadLetter) any { return dl.ID },
+		SortByCreatedAt: func(dl types.DeadLetter) any { return dl.CreatedAt },
+	},
+}
+
+// Position is the sort value and ID of the record a cursor points after.
+type Position struct {
+	Value any
+	ID    any
+}
+
+type cursor struct {
+	Field      string          `json:"f"`
+	Descending bool            `json:"d,omitempty"`
+	Value      json.RawMessage `json:"v"`
+	ID         json.RawMessage `json:"id"`
+}
+
+// Normalize fills in the default sort field and checks that the options can
+// be used with this ordering.
+func (o Ordering[T]) Normalize(options ListOptions) (ListOptions, error) {
+	if options.Sort.Field == "" {
+		options.Sort.Field = SortByID
+	}
+
+	if _, ok := o.Fields[options.Sort.Field]; !ok {
+		return ListOptions{}, ErrInvalidSortField
+	}
+
+	if options.Limit < 0 {
+		return ListOptions{}, ErrInvalidLimit
+	}
+
+	return options, nil
+}
+
+// SortFields lists the fields this ordering can sort by.
+func (o Ordering[T]) SortFields() []string {
+	fields := make([]string, 0, len(o.Fields))
+	for field := range o.Fields {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+// Position decodes the cursor of normalized options. It returns nil when the
+// listing starts at the beginning.
+func (o Ordering[T]) Position(options ListOptions) (*Position, error) {
+	if options.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(options.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Field != options.Sort.Field || c.Descending != options.Sort.Descending {
+		return nil, ErrInvalidCursor
+	}
+
+	var zero T
+
+	value, err := decodeLike(o.Fields[c.Field](zero), c.Value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := decodeLike(o.ID(zero), c.ID)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Position{Value: value, ID: id}, nil
+}
+
+// decodeLike decodes data into a value of the same type as sample.
+func decodeLike(sample any, data json.RawMessage) (any, error) {
+	ptr := reflect.New(reflect.TypeOf(sample))
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+// Cursor returns the cursor pointing right after item.
+func (o Ordering[T]) Cursor(options ListOptions, item T) string {
+	value, _ := json.Marshal(o.Fields[options.Sort.Field](item))
+	id, _ := json.Marshal(o.ID(item))
+
+	data, _ := json.Marshal(cursor{
+		Field:      options.Sort.Field,
+		Descending: options.Sort.Descending,
+		Value:      value,
+		ID:         id,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Compare orders two records by the sort of normalized options.
+func (o Ordering[T]) Compare(options ListOptions, a T, b T) int {
+	return o.compare(options.Sort, o.Fields[options.Sort.Field](a), o.ID(a), o.Fields[options.Sort.Field](b), o.ID(b))
+}
+
+// After reports whether item comes after position in the sort of normalized options.
+func (o Ordering[T]) After(options ListOptions, position *Position, item T) bool {
+	if position == nil {
+		return true
+	}
+
+	return o.compare(options.Sort, o.Fields[options.Sort.Field](item), o.ID(item), position.Value, position.ID) > 0
+}
+
+func (o Ordering[T]) compare(s Sort, valueA any, idA any, valueB any, idB any) int {
+	result := compareValues(valueA, valueB)
+	if result == 0 {
+		result = compareValues(idA, idB)
+	}
+
+	if s.Descending {
+		return -result
+	}
+
+	return result
+}
+
+func compareValues(a any, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int:
+		switch {
+		case a < b.(int):
+			return -1
+		case a > b.(int):
+			return 1
+		default:
+			return 0
+		}
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		panic("datastore: unsupported sort value type")
+	}
+}
+
+// NewPage builds a page from items already sorted and positioned after the
+// cursor. Backends should fetch up to options.Limit+1 items, so the page
+// knows whether there is a next one.
+func (o Ordering[T]) NewPage(options ListOptions, items []T) Page[T] {
+	if options.Limit == 0 || len(items) <= options.Limit {
+		return Page[T]{Items: items}
+	}
+
+	items = items[:options.Limit]
+	return Page[T]{
+		Items:      items,
+		NextCursor: o.Cursor(options, items[len(items)-1]),
+	}
+}
+
+// Paginate sorts items and returns the page described by options. It is meant
+// for backends that can not sort or seek natively.
+func (o Ordering[T]) Paginate(items []T, options ListOptions) (Page[T], error) {
+	options, err := o.Normalize(options)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	position, err := o.Position(options)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	var sorted []T
+	for _, item := range items {
+		if o.After(options, position, item) {
+			sorted = append(sorted, item)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return o.Compare(options, sorted[i], sorted[j]) < 0
+	})
+
+	return o.NewPage(options, sorted), nil
+}
@@ -0,0 +1,150 @@
+package datastore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func TestOrderingPosition(t *testing.T) {
+	command := types.Command{ID: "cmd1", IssuedAt: time.Date(2009, 11, 10, 23, 0, 0, 123456789, time.UTC)}
+	subscription := types.ReportSubscription{ID: 12, UpdatedAt: time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+
+		inputOptions  ListOptions
+		inputCursor   func(options ListOptions) string
+		inputPosition func(options ListOptions) (*Position, error)
+		expected      *Position
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name:         "Success - Time Value",
+			inputOptions: ListOptions{Sort: Sort{Field: SortByIssuedAt, Descending: true}},
+			inputCursor: func(options ListOptions) string {
+				return CommandOrdering.Cursor(options, command)
+			},
+			inputPosition: CommandOrdering.Position,
+			expected:      &Position{Value: command.IssuedAt, ID: command.ID},
+		},
+		{
+			name:         "Success - Int ID",
+			inputOptions: ListOptions{Sort: Sort{Field: SortByID}},
+			inputCursor: func(options ListOptions) string {
+				return ReportSubscriptionOrdering.Cursor(options, subscription)
+			},
+			inputPosition: ReportSubscriptionOrdering.Position,
+			expected:      &Position{Value: subscription.ID, ID: subscription.ID},
+		},
+		{
+			name:         "Success - No Cursor",
+			inputOptions: ListOptions{Sort: Sort{Field: SortByID}},
+			inputCursor: func(options ListOptions) string {
+				return ""
+			},
+			inputPosition: CommandOrdering.Position,
+			expected:      nil,
+		},
+		{
+			name:         "Error - Sort Mismatch",
+			inputOptions: ListOptions{Sort: Sort{Field: SortByIssuedAt}},
+			inputCursor: func(options ListOptions) string {
+				return CommandOrdering.Cursor(ListOptions{Sort: Sort{Field: SortByID}}, command)
+			},
+			inputPosition: CommandOrdering.Position,
+			wantErr:       true,
+			expectedErr:   ErrInvalidCursor,
+		},
+		{
+			name:         "Error - Malformed Cursor",
+			inputOptions: ListOptions{Sort: Sort{Field: SortByID}},
+			inputCursor: func(options ListOptions) string {
+				return "not a cursor"
+			},
+			inputPosition: CommandOrdering.Position,
+			wantErr:       true,
+			expectedErr:   ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.inputOptions
+			options.Cursor = tt.inputCursor(options)
+
+			got, err := tt.inputPosition(options)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	entities := []types.Entity{
+		{ID: "3", Name: "a"},
+		{ID: "1", Name: "b"},
+		{ID: "2", Name: "a"},
+	}
+
+	tests := []struct {
+		name string
+
+		inputOptions ListOptions
+		expected     []types.Entity
+		expectedNext bool
+	}{
+		{
+			name:         "Default Sort",
+			inputOptions: ListOptions{},
+			expected:     []types.Entity{entities[1], entities[2], entities[0]},
+		},
+		{
+			name:         "Sort by: Name, Ties by ID",
+			inputOptions: ListOptions{Limit: 2, Sort: Sort{Field: SortByName}},
+			expected:     []types.Entity{entities[2], entities[0]},
+			expectedNext: true,
+		},
+		{
+			name:         "Exact Limit",
+			inputOptions: ListOptions{Limit: 3},
+			expected:     []types.Entity{entities[1], entities[2], entities[0]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EntityOrdering.Paginate(entities, tt.inputOptions)
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got.Items) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got.Items)
+			}
+
+			if tt.expectedNext != (got.NextCursor != "") {
+				t.Errorf("Test failed. Expected next cursor: %v, Got: %q", tt.expectedNext, got.NextCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"fmt"
+)
+
+// Migration is a numbered schema change. Tx is the backend specific handle
+// the change is applied with, e.g. *bbolt.Tx or *sql.Tx.
+type Migration[Tx any] struct {
+	Version     int
+	Description string
+	Apply       func(tx Tx) error
+}
+
+type MigrationStatus struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Applied     bool   `json:"applied"`
+}
+
+// Migrator is implemented by datastores that keep track of their schema
+// version. Init applies every pending migration in order.
+type Migrator interface {
+	SchemaVersion() (int, error)
+	MigrationStatus() ([]MigrationStatus, error)
+}
+
+// PendingMigrations returns the migrations newer than the current version, in order.
+func PendingMigrations[Tx any](migrations []Migration[Tx], current int) []Migration[Tx] {
+	var pending []Migration[Tx]
+	for _, migration := range migrations {
+		if migration.Version > current {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
+
+// MigrationStatusList reports which migrations are applied at the current version.
+func MigrationStatusList[Tx any](migrations []Migration[Tx], current int) []MigrationStatus {
+	statusList := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		statusList = append(statusList, MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     migration.Version <= current,
+		})
+	}
+
+	return statusList
+}
+
+// ValidateMigrations checks that versions are numbered 1, 2, 3... without gaps.
+func ValidateMigrations[Tx any](migrations []Migration[Tx]) error {
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return fmt.Errorf("migration %q has version %d, expected %d", migration.Description, migration.Version, i+1)
+		}
+	}
+
+	return nil
+}
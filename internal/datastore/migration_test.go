@@ -0,0 +1,104 @@
+package datastore
+
+import (
+	"reflect"
+	"testing"
+)
+
+var mockMigrations = []Migration[any]{
+	{Version: 1, Description: "first"},
+	{Version: 2, Description: "second"},
+	{Version: 3, Description: "third"},
+}
+
+func TestPendingMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputCurrent int
+		expected     []int
+	}{
+		{
+			name:         "Fresh Schema",
+			inputCurrent: 0,
+			expected:     []int{1, 2, 3},
+		},
+		{
+			name:         "Partially Migrated",
+			inputCurrent: 2,
+			expected:     []int{3},
+		},
+		{
+			name:         "Up To Date",
+			inputCurrent: 3,
+			expected:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, migration := range PendingMigrations(mockMigrations, tt.inputCurrent) {
+				got = append(got, migration.Version)
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %v, Got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMigrationStatusList(t *testing.T) {
+	expected := []MigrationStatus{
+		{Version: 1, Description: "first", Applied: true},
+		{Version: 2, Description: "second", Applied: false},
+		{Version: 3, Description: "third", Applied: false},
+	}
+
+	got := MigrationStatusList(mockMigrations, 1)
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+	}
+}
+
+func TestValidateMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputMigrations []Migration[any]
+		wantErr         bool
+	}{
+		{
+			name:            "Valid",
+			inputMigrations: mockMigrations,
+		},
+		{
+			name: "Gap",
+			inputMigrations: []Migration[any]{
+				{Version: 1, Description: "first"},
+				{Version: 3, Description: "third"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Out Of Order",
+			inputMigrations: []Migration[any]{
+				{Version: 2, Description: "second"},
+				{Version: 1, Description: "first"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMigrations(tt.inputMigrations)
+
+			if tt.wantErr != (err != nil) {
+				t.Errorf("Test failed. Expected error: %v, Got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
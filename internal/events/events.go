@@ -0,0 +1,108 @@
+package events
+
+import (
+	"sync"
+)
+
+type Type string
+
+const (
+	TypeCommandCreated  Type = "command.created"
+	TypeCommandResolved Type = "command.resolved"
+	TypeStateReported   Type = "state.reported"
+)
+
+// subscriptionBuffer is how many events a subscriber can fall behind before
+// it is dropped.
+const subscriptionBuffer = 32
+
+// Event is a change that happened inside the service, e.g. a command being
+// resolved. Data is the changed resource.
+type Event struct {
+	Type      Type
+	EntityID  string
+	CommandID string
+	Data      any
+}
+
+// Matcher selects the events a subscriber receives.
+type Matcher func(event Event) bool
+
+func ByEntityID(entityID string) Matcher {
+	return func(event Event) bool {
+		return event.EntityID == entityID
+	}
+}
+
+func ByCommandID(commandID string) Matcher {
+	return func(event Event) bool {
+		return event.CommandID == commandID
+	}
+}
+
+// Bus delivers events to the subscribers of this instance of the service.
+//
+// Publishing never blocks: a subscriber that does not keep up has its channel
+// closed, and has to subscribe again.
+type Bus struct {
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+}
+
+type subscription struct {
+	match  Matcher
+	events chan Event
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+// Subscribe returns the channel of the events matched, and a function to
+// unsubscribe, which closes the channel.
+func (b *Bus) Subscribe(match Matcher) (<-chan Event, func()) {
+	sub := &subscription{
+		match:  match,
+		events: make(chan Event, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.remove(sub)
+	}
+}
+
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscriptions {
+		if !sub.match(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// remove must be called with the lock held.
+func (b *Bus) remove(sub *subscription) {
+	if _, ok := b.subscriptions[sub]; !ok {
+		return
+	}
+
+	delete(b.subscriptions, sub)
+	close(sub.events)
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	published := []Event{
+		{Type: TypeCommandCreated, EntityID: "1", CommandID: "cmd1"},
+		{Type: TypeStateReported, EntityID: "2"},
+		{Type: TypeStateReported, EntityID: "1"},
+		{Type: TypeCommandResolved, EntityID: "1", CommandID: "cmd1"},
+	}
+
+	tests := []struct {
+		name string
+
+		inputMatcher Matcher
+		expected     []Event
+	}{
+		{
+			name:         "By Entity ID",
+			inputMatcher: ByEntityID("1"),
+			expected:     []Event{published[0], published[2], published[3]},
+		},
+		{
+			name:         "By Command ID",
+			inputMatcher: ByCommandID("cmd1"),
+			expected:     []Event{published[0], published[3]},
+		},
+		{
+			name:         "No Match",
+			inputMatcher: ByEntityID("3"),
+			expected:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+
+			stream, unsubscribe := bus.Subscribe(tt.inputMatcher)
+
+			for _, event := range published {
+				bus.Publish(event)
+			}
+
+			unsubscribe()
+
+			var got []Event
+			for event := range stream {
+				got = append(got, event)
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBusSlowSubscriber(t *testing.T) {
+	bus := NewBus()
+
+	stream, unsubscribe := bus.Subscribe(ByEntityID("1"))
+	defer unsubscribe()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		bus.Publish(Event{Type: TypeStateReported, EntityID: "1"})
+	}
+
+	got := 0
+	for range stream {
+		got++
+	}
+
+	if got != subscriptionBuffer {
+		t.Errorf("Test failed. Expected: %d, Got: %d", subscriptionBuffer, got)
+	}
+
+	// publishing to a dropped subscriber, or unsubscribing it, is a no-op
+	bus.Publish(Event{Type: TypeStateReported, EntityID: "1"})
+}
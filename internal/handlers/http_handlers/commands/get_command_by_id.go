@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetCommandByID(c *gin.Context) {
+	commandID := c.Param("command_id")
+	if commandID == "" {
+		err := errors.New("'command_id' missing from path")
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	command, err := http_handlers.CommandService.GetCommandByID(commandID)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrCommandNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/events"
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamCommandEvents streams the status changes of a command. The stream
+// starts with the command as it is, and ends once it is resolved, by this
+// instance of the service or, checked on every keep-alive, by another one.
+func StreamCommandEvents(c *gin.Context) {
+	commandID := c.Param("command_id")
+	if commandID == "" {
+		err := errors.New("'command_id' missing from path")
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	// subscribe before getting the command, so a resolution in between is
+	// not missed
+	stream, unsubscribe := http_handlers.Events.Subscribe(events.ByCommandID(commandID))
+	defer unsubscribe()
+
+	command, err := http_handlers.CommandService.GetCommandByID(commandID)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrCommandNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	isResolved := func(event events.Event) bool {
+		return event.Type == events.TypeCommandResolved
+	}
+
+	refresh := func() (events.Event, bool) {
+		command, err := http_handlers.CommandService.GetCommandByID(commandID)
+		if err != nil || command.Status == types.CommandStatusPending {
+			return events.Event{}, false
+		}
+
+		return commandEvent(command), true
+	}
+
+	http_handlers.StreamEvents(c, stream, isResolved, refresh, commandEvent(command))
+}
+
+// commandEvent is the event of the current status of a command.
+func commandEvent(command types.Command) events.Event {
+	eventType := events.TypeCommandCreated
+	if command.Status != types.CommandStatusPending {
+		eventType = events.TypeCommandResolved
+	}
+
+	return events.Event{
+		Type:      eventType,
+		EntityID:  command.EntityID,
+		CommandID: command.ID,
+		Data:      command,
+	}
+}
@@ -0,0 +1,35 @@
+package dead_letters
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetDeadLetterByID(c *gin.Context) {
+	deadLetterID, err := pathParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	deadLetter, err := http_handlers.DeadLetterService.GetDeadLetterByID(deadLetterID)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrDeadLetterNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, deadLetter)
+}
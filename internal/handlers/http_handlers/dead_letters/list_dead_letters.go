@@ -0,0 +1,36 @@
+package dead_letters
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListDeadLetters(c *gin.Context) {
+	options, err := http_handlers.ListOptionsFromQuery(c, datastore.DeadLetterOrdering)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	page, err := http_handlers.DeadLetterService.ListDeadLetters(options)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrInvalidCursor), errors.Is(err, services.ErrInvalidSortField):
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, http_handlers.PageMessage(page))
+}
@@ -0,0 +1,17 @@
+package dead_letters
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func pathParams(c *gin.Context) (int, error) {
+	deadLetterID, err := strconv.Atoi(c.Param("dead_letter_id"))
+	if err != nil {
+		return 0, errors.New("'dead_letter_id' must be an integer")
+	}
+
+	return deadLetterID, nil
+}
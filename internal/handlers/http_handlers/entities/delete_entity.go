@@ -3,6 +3,7 @@ package entities
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
 	"github.com/pmoura-dev/esr-service/internal/services"
@@ -18,8 +19,25 @@ func DeleteEntity(c *gin.Context) {
 		return
 	}
 
-	err := http_handlers.EntityService.DeleteEntity(entityID)
+	cascade := false
+	if rawCascade := c.Query("cascade"); rawCascade != "" {
+		parsed, err := strconv.ParseBool(rawCascade)
+		if err != nil {
+			err = errors.New("'cascade' must be a boolean")
+			c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+			return
+		}
+		cascade = parsed
+	}
+
+	err := http_handlers.EntityService.DeleteEntity(entityID, cascade)
 	if err != nil {
+		var referencedErr *services.EntityReferencedError
+		if errors.As(err, &referencedErr) {
+			c.JSON(http.StatusConflict, http_handlers.ReferencedErrorMessage(err, referencedErr.References))
+			return
+		}
+
 		var status int
 		switch {
 		case errors.Is(err, services.ErrEntityNotFound):
@@ -1,19 +1,36 @@
 package entities
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/pmoura-dev/esr-service/internal/datastore"
 	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 func ListEntities(c *gin.Context) {
-	entityList, err := http_handlers.EntityService.ListEntities()
+	options, err := http_handlers.ListOptionsFromQuery(c, datastore.EntityOrdering)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, http_handlers.ErrorMessage(err))
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, entityList)
+	page, err := http_handlers.EntityService.ListEntities(options)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrInvalidCursor), errors.Is(err, services.ErrInvalidSortField):
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, http_handlers.PageMessage(page))
 }
@@ -3,6 +3,7 @@ package entities
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
@@ -17,13 +18,24 @@ func NewCommand(c *gin.Context) {
 		return
 	}
 
+	var timeout time.Duration
+	if rawTimeout := c.Query("timeout"); rawTimeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(rawTimeout)
+		if err != nil || timeout <= 0 {
+			err := errors.New("'timeout' must be a positive duration")
+			c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+			return
+		}
+	}
+
 	var desiredState map[string]any
 	if err := c.ShouldBindJSON(&desiredState); err != nil {
 		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(http_handlers.ErrInvalidJSONBody))
 		return
 	}
 
-	commandID, err := http_handlers.EntityService.ProcessCommand(entityID, desiredState)
+	commandID, err := http_handlers.EntityService.ProcessCommand(entityID, desiredState, timeout)
 	if err != nil {
 		var status int
 		switch {
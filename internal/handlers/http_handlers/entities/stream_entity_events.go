@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pmoura-dev/esr-service/internal/events"
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+)
+
+// StreamEntityEvents streams the commands and state reports of an entity as
+// they happen, until the client disconnects.
+func StreamEntityEvents(c *gin.Context) {
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		err := errors.New("'entity_id' missing from path")
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	stream, unsubscribe := http_handlers.Events.Subscribe(events.ByEntityID(entityID))
+	defer unsubscribe()
+
+	if _, err := http_handlers.EntityService.GetEntityByID(entityID); err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrEntityNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	neverLast := func(event events.Event) bool {
+		return false
+	}
+
+	http_handlers.StreamEvents(c, stream, neverLast, nil)
+}
@@ -0,0 +1,71 @@
+package http_handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keepAliveInterval is how often an idle stream sends a comment, so proxies
+// do not close it.
+const keepAliveInterval = 15 * time.Second
+
+// StreamEvents writes the initial events, then the events of the stream, as
+// Server-Sent Events. The stream ends after an event isLast reports, when the
+// client disconnects, or when the stream is closed because the client fell
+// behind, in which case the client is expected to reconnect.
+//
+// Events are only published to the instance of the service that produced
+// them, so refresh, when not nil, is called instead of sending a keep-alive
+// and returns the event of a change made by another instance, if any.
+func StreamEvents(c *gin.Context, stream <-chan events.Event, isLast func(event events.Event) bool, refresh func() (events.Event, bool), initial ...events.Event) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	// send the headers right away, clients may wait for them before the
+	// first event
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for _, event := range initial {
+		c.SSEvent(string(event.Type), event.Data)
+		if isLast(event) {
+			return
+		}
+	}
+
+	// c.Stream only flushes after a step, which may be a keep-alive later
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+
+			c.SSEvent(string(event.Type), event.Data)
+			return !isLast(event)
+		case <-keepAlive.C:
+			if refresh != nil {
+				if event, ok := refresh(); ok {
+					c.SSEvent(string(event.Type), event.Data)
+					return !isLast(event)
+				}
+			}
+
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
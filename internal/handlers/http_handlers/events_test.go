@@ -0,0 +1,77 @@
+package http_handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	stream := make(chan events.Event, 1)
+	isLast := func(event events.Event) bool {
+		return event.Type == events.TypeCommandResolved
+	}
+
+	router := gin.New()
+	router.GET("/events", func(c *gin.Context) {
+		StreamEvents(c, stream, isLast, nil, events.Event{Type: events.TypeCommandCreated, Data: "created"})
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "event:") {
+				lines <- line
+			}
+		}
+	}()
+
+	// receive returns the type of the next event, failing when it is not
+	// sent in time
+	receive := func(t *testing.T) string {
+		t.Helper()
+
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("Test failed. Stream ended")
+			}
+			return strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case <-time.After(time.Second):
+			t.Fatalf("Test failed. Event was not sent")
+			return ""
+		}
+	}
+
+	// the initial event is sent before any event of the stream
+	if got := receive(t); got != string(events.TypeCommandCreated) {
+		t.Errorf("Test failed. Expected: %s, Got: %s", events.TypeCommandCreated, got)
+	}
+
+	stream <- events.Event{Type: events.TypeCommandResolved, Data: "resolved"}
+
+	if got := receive(t); got != string(events.TypeCommandResolved) {
+		t.Errorf("Test failed. Expected: %s, Got: %s", events.TypeCommandResolved, got)
+	}
+}
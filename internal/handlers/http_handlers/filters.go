@@ -0,0 +1,108 @@
+package http_handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommandFilterFromQuery builds a command filter from the 'entity_id', 'status',
+// 'issued_after' and 'issued_before' query parameters, and from a boolean
+// expression in the 'filter' query parameter, e.g.
+// 'status in (pending, failure) and not entity_id = "1"'.
+func CommandFilterFromQuery(c *gin.Context) (datastore.Filter[types.Command], error) {
+	filter := filters.NewCommandFilter()
+
+	if entityID := c.Query("entity_id"); entityID != "" {
+		filter.ByEntityID(entityID)
+	}
+
+	if rawStatus := c.Query("status"); rawStatus != "" {
+		status, err := types.ParseCommandStatus(rawStatus)
+		if err != nil {
+			return nil, fmt.Errorf("'status' is invalid: %w", err)
+		}
+		filter.ByStatus(status)
+	}
+
+	if rawIssuedAfter := c.Query("issued_after"); rawIssuedAfter != "" {
+		issuedAfter, err := time.Parse(time.RFC3339, rawIssuedAfter)
+		if err != nil {
+			return nil, errors.New("'issued_after' must be an RFC3339 timestamp")
+		}
+		filter.ByTimeAfterIssuing(issuedAfter)
+	}
+
+	if rawIssuedBefore := c.Query("issued_before"); rawIssuedBefore != "" {
+		issuedBefore, err := time.Parse(time.RFC3339, rawIssuedBefore)
+		if err != nil {
+			return nil, errors.New("'issued_before' must be an RFC3339 timestamp")
+		}
+		filter.ByTimeBeforeIssuing(issuedBefore)
+	}
+
+	return withQueryFilter[types.Command](c, filter, filters.CommandQueryFields)
+}
+
+// ReportSubscriptionFilterFromQuery builds a report subscription filter from the
+// 'report_type', 'is_active', 'updated_after' and 'updated_before' query
+// parameters, and from a boolean expression in the 'filter' query parameter.
+func ReportSubscriptionFilterFromQuery(c *gin.Context) (datastore.Filter[types.ReportSubscription], error) {
+	filter := filters.NewReportSubscriptionFilter()
+
+	if rawReportType := c.Query("report_type"); rawReportType != "" {
+		reportType, err := types.ParseReportType(rawReportType)
+		if err != nil {
+			return nil, fmt.Errorf("'report_type' is invalid: %w", err)
+		}
+		filter.ByReportType(reportType)
+	}
+
+	if rawIsActive := c.Query("is_active"); rawIsActive != "" {
+		isActive, err := strconv.ParseBool(rawIsActive)
+		if err != nil {
+			return nil, errors.New("'is_active' must be a boolean")
+		}
+		filter.ByIsActive(isActive)
+	}
+
+	if rawUpdatedAfter := c.Query("updated_after"); rawUpdatedAfter != "" {
+		updatedAfter, err := time.Parse(time.RFC3339, rawUpdatedAfter)
+		if err != nil {
+			return nil, errors.New("'updated_after' must be an RFC3339 timestamp")
+		}
+		filter.ByTimeAfterUpdated(updatedAfter)
+	}
+
+	if rawUpdatedBefore := c.Query("updated_before"); rawUpdatedBefore != "" {
+		updatedBefore, err := time.Parse(time.RFC3339, rawUpdatedBefore)
+		if err != nil {
+			return nil, errors.New("'updated_before' must be an RFC3339 timestamp")
+		}
+		filter.ByTimeBeforeUpdated(updatedBefore)
+	}
+
+	return withQueryFilter[types.ReportSubscription](c, filter, filters.ReportSubscriptionQueryFields)
+}
+
+// withQueryFilter combines filter with the expression in the 'filter' query parameter, if any.
+func withQueryFilter[T any](c *gin.Context, filter datastore.Filter[T], fields filters.QueryFields[T]) (datastore.Filter[T], error) {
+	rawFilter, ok := c.GetQuery("filter")
+	if !ok {
+		return filter, nil
+	}
+
+	queryFilter, err := filters.ParseQuery(rawFilter, fields)
+	if err != nil {
+		return nil, fmt.Errorf("'filter' is invalid: %w", err)
+	}
+
+	return filters.And(filter, queryFilter), nil
+}
@@ -4,12 +4,19 @@ import (
 	"errors"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pmoura-dev/esr-service/internal/events"
 	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
 	"github.com/pmoura-dev/esr-service/internal/validation"
 )
 
 var (
-	EntityService services.EntityService
+	EntityService             services.EntityService
+	CommandService            services.CommandService
+	ReportSubscriptionService services.ReportSubscriptionService
+	DeadLetterService         services.DeadLetterService
+
+	Events *events.Bus
 )
 
 var (
@@ -30,6 +37,13 @@ func ErrorMessage(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
 
+func ReferencedErrorMessage(err error, references types.EntityReferences) gin.H {
+	return gin.H{
+		"error":      err.Error(),
+		"references": references,
+	}
+}
+
 func ValidationErrorMessage(errorList validation.ErrorList) gin.H {
 	return gin.H{
 		"error":   "Validation Error",
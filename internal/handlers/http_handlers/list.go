@@ -0,0 +1,65 @@
+package http_handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// ListOptionsFromQuery builds list options from the 'limit', 'cursor' and 'sort'
+// query parameters. A sort field prefixed with '-' sorts in descending order.
+func ListOptionsFromQuery[T any](c *gin.Context, ordering datastore.Ordering[T]) (datastore.ListOptions, error) {
+	options := datastore.ListOptions{
+		Limit:  DefaultListLimit,
+		Cursor: c.Query("cursor"),
+	}
+
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > MaxListLimit {
+			return datastore.ListOptions{}, fmt.Errorf("'limit' must be an integer between 1 and %d", MaxListLimit)
+		}
+		options.Limit = limit
+	}
+
+	if rawSort := c.Query("sort"); rawSort != "" {
+		field, descending := strings.CutPrefix(rawSort, "-")
+
+		sortFields := ordering.SortFields()
+		if !slices.Contains(sortFields, field) {
+			return datastore.ListOptions{}, fmt.Errorf("'sort' must be one of: %s", strings.Join(sortFields, ", "))
+		}
+
+		options.Sort = datastore.Sort{Field: field, Descending: descending}
+	}
+
+	return options, nil
+}
+
+// PageMessage wraps the items of a page in the list response envelope.
+func PageMessage[T any](page datastore.Page[T]) gin.H {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	var nextCursor *string
+	if page.NextCursor != "" {
+		nextCursor = &page.NextCursor
+	}
+
+	return gin.H{
+		"items":       items,
+		"next_cursor": nextCursor,
+	}
+}
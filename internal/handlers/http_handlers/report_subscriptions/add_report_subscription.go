@@ -0,0 +1,52 @@
+package report_subscriptions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AddReportSubscription(c *gin.Context) {
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		err := errors.New("'entity_id' missing from path")
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	var subscription types.ReportSubscription
+
+	if err := c.ShouldBindJSON(&subscription); err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(http_handlers.ErrInvalidJSONBody))
+		return
+	}
+
+	subscription.ID = 0
+	subscription.EntityID = entityID
+
+	if errorList := subscription.Validate(); len(errorList) > 0 {
+		c.JSON(http.StatusBadRequest, http_handlers.ValidationErrorMessage(errorList))
+		return
+	}
+
+	subscription, err := http_handlers.ReportSubscriptionService.AddReportSubscription(subscription)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrEntityNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
@@ -0,0 +1,35 @@
+package report_subscriptions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DeleteReportSubscription(c *gin.Context) {
+	entityID, subscriptionID, err := pathParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	err = http_handlers.ReportSubscriptionService.DeleteReportSubscription(entityID, subscriptionID)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrReportSubscriptionNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
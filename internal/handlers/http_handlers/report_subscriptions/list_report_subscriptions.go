@@ -0,0 +1,64 @@
+package report_subscriptions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/handlers/http_handlers"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListReportSubscriptions(c *gin.Context) {
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		err := errors.New("'entity_id' missing from path")
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	filter, err := http_handlers.ReportSubscriptionFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	options, err := http_handlers.ListOptionsFromQuery(c, datastore.ReportSubscriptionOrdering)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	if _, err := http_handlers.EntityService.GetEntityByID(entityID); err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrEntityNotFound):
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	page, err := http_handlers.ReportSubscriptionService.ListReportSubscriptions(filters.And[types.ReportSubscription](filters.NewReportSubscriptionFilter().ByEntityID(entityID), filter), options)
+	if err != nil {
+		var status int
+		switch {
+		case errors.Is(err, services.ErrInvalidCursor), errors.Is(err, services.ErrInvalidSortField):
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, http_handlers.ErrorMessage(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, http_handlers.PageMessage(page))
+}
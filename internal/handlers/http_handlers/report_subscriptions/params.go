@@ -0,0 +1,22 @@
+package report_subscriptions
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func pathParams(c *gin.Context) (string, int, error) {
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		return "", 0, errors.New("'entity_id' missing from path")
+	}
+
+	subscriptionID, err := strconv.Atoi(c.Param("subscription_id"))
+	if err != nil {
+		return "", 0, errors.New("'subscription_id' must be an integer")
+	}
+
+	return entityID, subscriptionID, nil
+}
@@ -0,0 +1,56 @@
+package pubsub_handlers
+
+import (
+	"maps"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+)
+
+const (
+	// DeadLetterTopic is the poison queue topic. Dead letters are stored
+	// rather than published, so it is never sent to the broker.
+	DeadLetterTopic = "dead-letters"
+)
+
+// DeadLetterPublisher is the publisher of the poison queue middleware. It
+// stores the messages no handler could process as dead letters.
+type DeadLetterPublisher struct{}
+
+func (DeadLetterPublisher) Publish(_ string, messages ...*message.Message) error {
+	for _, msg := range messages {
+		metadata := maps.Clone(map[string]string(msg.Metadata))
+
+		deadLetter := types.DeadLetter{
+			MessageID: msg.UUID,
+			Handler:   metadata[middleware.PoisonedHandlerKey],
+			Topic:     metadata[middleware.PoisonedTopicKey],
+			Payload:   msg.Payload,
+			Metadata:  metadata,
+			Reason:    metadata[middleware.ReasonForPoisonedKey],
+		}
+
+		// the poison queue details have their own fields, the metadata is
+		// kept as received so the message can be replayed
+		for _, key := range []string{
+			middleware.PoisonedHandlerKey,
+			middleware.PoisonedTopicKey,
+			middleware.PoisonedSubscriberKey,
+			middleware.ReasonForPoisonedKey,
+		} {
+			delete(metadata, key)
+		}
+
+		if _, err := DeadLetterService.AddDeadLetter(deadLetter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (DeadLetterPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/pubsub_handlers"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	AckCommandTopic = "entities/*/commands/*/ack"
+)
+
+func AckCommand(msg *message.Message) error {
+	event, err := pubsub_handlers.Codec.Decode(msg)
+	if err != nil {
+		return err
+	}
+
+	var ack types.CommandAck
+
+	if err := json.Unmarshal(event.Data, &ack); err != nil {
+		return pubsub_handlers.ErrInvalidJSONPayload
+	}
+
+	if errorList := ack.Validate(); len(errorList) > 0 {
+		return fmt.Errorf("%w: %+v", pubsub_handlers.ErrValidationFailed, errorList)
+	}
+
+	if err := pubsub_handlers.CheckTopic(msg, AckCommandTopic, ack.EntityID, ack.CommandID); err != nil {
+		return err
+	}
+
+	return pubsub_handlers.EntityService.ProcessCommandAck(ack.EntityID, ack.CommandID, ack.Status, ack.Error.Reason())
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/handlers/pubsub_handlers"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	ReportStateTopic = "entities/*/state"
+)
+
+func ReportState(msg *message.Message) error {
+	event, err := pubsub_handlers.Codec.Decode(msg)
+	if err != nil {
+		return err
+	}
+
+	var report types.StateReport
+
+	if err := json.Unmarshal(event.Data, &report); err != nil {
+		return pubsub_handlers.ErrInvalidJSONPayload
+	}
+
+	if errorList := report.Validate(); len(errorList) > 0 {
+		return fmt.Errorf("%w: %+v", pubsub_handlers.ErrValidationFailed, errorList)
+	}
+
+	if err := pubsub_handlers.CheckTopic(msg, ReportStateTopic, report.EntityID); err != nil {
+		return err
+	}
+
+	reportedAt := time.Now()
+	if report.ReportedAt != nil {
+		reportedAt = *report.ReportedAt
+	}
+
+	return pubsub_handlers.EntityService.ProcessStateReport(report.EntityID, report.State, reportedAt)
+}
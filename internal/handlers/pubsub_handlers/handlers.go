@@ -1 +1,22 @@
 package pubsub_handlers
+
+import (
+	"errors"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/services"
+)
+
+var (
+	EntityService     services.EntityService
+	DeadLetterService services.DeadLetterService
+
+	// Codec decodes the events carried by inbound messages.
+	Codec codec.Codec
+)
+
+var (
+	ErrInvalidJSONPayload = errors.New("invalid JSON payload")
+	ErrValidationFailed   = errors.New("payload validation failed")
+	ErrTopicMismatch      = errors.New("payload does not match topic")
+)
@@ -0,0 +1,45 @@
+package pubsub_handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	topicWildcard = "*"
+	topicLevelSep = "/"
+)
+
+// CheckTopic checks that a message was published to the topic of the IDs in
+// its payload, i.e. pattern with its wildcards replaced by values in order, so
+// a device can not report for another one. Messages received on an unknown
+// topic are rejected.
+func CheckTopic(msg *message.Message, pattern string, values ...string) error {
+	topic := msg.Metadata.Get(codec.MetadataReceivedTopic)
+	if topic == "" {
+		return fmt.Errorf("%w: the topic the message was received on is unknown", ErrTopicMismatch)
+	}
+
+	levels := strings.Split(pattern, topicLevelSep)
+	for i, level := range levels {
+		if level != topicWildcard {
+			continue
+		}
+
+		if len(values) == 0 {
+			break
+		}
+
+		levels[i], values = values[0], values[1:]
+	}
+
+	if expected := strings.Join(levels, topicLevelSep); topic != expected {
+		return fmt.Errorf("%w: received on '%s', expected '%s'", ErrTopicMismatch, topic, expected)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package pubsub_handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestCheckTopic(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputTopic   string
+		inputPattern string
+		inputValues  []string
+		wantErr      bool
+	}{
+		{
+			name:         "Matching Topic",
+			inputTopic:   "entities/1/commands/cmd1/ack",
+			inputPattern: "entities/*/commands/*/ack",
+			inputValues:  []string{"1", "cmd1"},
+		},
+		{
+			name:         "Error - Unknown Topic",
+			inputPattern: "entities/*/state",
+			inputValues:  []string{"1"},
+			wantErr:      true,
+		},
+		{
+			name:         "Error - Other Entity",
+			inputTopic:   "entities/2/state",
+			inputPattern: "entities/*/state",
+			inputValues:  []string{"1"},
+			wantErr:      true,
+		},
+		{
+			name:         "Error - Other Command",
+			inputTopic:   "entities/1/commands/cmd2/ack",
+			inputPattern: "entities/*/commands/*/ack",
+			inputValues:  []string{"1", "cmd1"},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := message.NewMessage("1", nil)
+			if tt.inputTopic != "" {
+				msg.Metadata.Set(codec.MetadataReceivedTopic, tt.inputTopic)
+			}
+
+			err := CheckTopic(msg, tt.inputPattern, tt.inputValues...)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrTopicMismatch) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", ErrTopicMismatch, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+			}
+		})
+	}
+}
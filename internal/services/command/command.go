@@ -0,0 +1,49 @@
+package command
+
+import (
+	"errors"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+type BaseCommandService struct {
+	datastore datastore.DataStore
+}
+
+func NewBaseCommandService(datastore datastore.DataStore) *BaseCommandService {
+	return &BaseCommandService{
+		datastore: datastore,
+	}
+}
+
+func (s *BaseCommandService) GetCommandByID(id string) (types.Command, error) {
+	command, err := s.datastore.GetCommandByID(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return types.Command{}, services.ErrCommandNotFound
+		default:
+			return types.Command{}, services.ErrInternalError
+		}
+	}
+
+	return command, nil
+}
+
+func (s *BaseCommandService) ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error) {
+	page, err := s.datastore.ListCommands(filter, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrInvalidCursor):
+			return datastore.Page[types.Command]{}, services.ErrInvalidCursor
+		case errors.Is(err, datastore.ErrInvalidSortField):
+			return datastore.Page[types.Command]{}, services.ErrInvalidSortField
+		default:
+			return datastore.Page[types.Command]{}, services.ErrInternalError
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,107 @@
+package dead_letter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type BaseDeadLetterService struct {
+	datastore datastore.DataStore
+	// handlers are the inbound message handlers, by the name they are
+	// registered with in the pubsub router.
+	handlers map[string]message.NoPublishHandlerFunc
+}
+
+func NewBaseDeadLetterService(datastore datastore.DataStore, handlers map[string]message.NoPublishHandlerFunc) *BaseDeadLetterService {
+	return &BaseDeadLetterService{
+		datastore: datastore,
+		handlers:  handlers,
+	}
+}
+
+func (s *BaseDeadLetterService) GetDeadLetterByID(id int) (types.DeadLetter, error) {
+	deadLetter, err := s.datastore.GetDeadLetterByID(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return types.DeadLetter{}, services.ErrDeadLetterNotFound
+		default:
+			return types.DeadLetter{}, services.ErrInternalError
+		}
+	}
+
+	return deadLetter, nil
+}
+
+func (s *BaseDeadLetterService) ListDeadLetters(options datastore.ListOptions) (datastore.Page[types.DeadLetter], error) {
+	page, err := s.datastore.ListDeadLetters(options)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrInvalidCursor):
+			return datastore.Page[types.DeadLetter]{}, services.ErrInvalidCursor
+		case errors.Is(err, datastore.ErrInvalidSortField):
+			return datastore.Page[types.DeadLetter]{}, services.ErrInvalidSortField
+		default:
+			return datastore.Page[types.DeadLetter]{}, services.ErrInternalError
+		}
+	}
+
+	return page, nil
+}
+
+func (s *BaseDeadLetterService) AddDeadLetter(deadLetter types.DeadLetter) (types.DeadLetter, error) {
+	deadLetter.CreatedAt = time.Now()
+
+	id, err := s.datastore.AddDeadLetter(deadLetter)
+	if err != nil {
+		return types.DeadLetter{}, services.ErrInternalError
+	}
+
+	deadLetter.ID = id
+	return deadLetter, nil
+}
+
+// ReplayDeadLetter hands the message to its handler directly, rather than
+// publishing it again, since the topic of a dead letter may be a pattern.
+func (s *BaseDeadLetterService) ReplayDeadLetter(id int) error {
+	deadLetter, err := s.GetDeadLetterByID(id)
+	if err != nil {
+		return err
+	}
+
+	handler, ok := s.handlers[deadLetter.Handler]
+	if !ok {
+		return fmt.Errorf("%w: unknown handler '%s'", services.ErrReplayFailed, deadLetter.Handler)
+	}
+
+	msg := message.NewMessage(deadLetter.MessageID, deadLetter.Payload)
+	for key, value := range deadLetter.Metadata {
+		msg.Metadata.Set(key, value)
+	}
+
+	if err := handler(msg); err != nil {
+		return fmt.Errorf("%w: %v", services.ErrReplayFailed, err)
+	}
+
+	return s.DeleteDeadLetter(id)
+}
+
+func (s *BaseDeadLetterService) DeleteDeadLetter(id int) error {
+	if err := s.datastore.DeleteDeadLetter(id); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrDeadLetterNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	return nil
+}
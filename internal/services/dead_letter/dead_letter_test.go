@@ -0,0 +1,107 @@
+package dead_letter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/services/servicetest"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestReplayDeadLetter(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputHandler string
+		inputID      int
+		wantDeleted  bool
+		wantErr      bool
+		expectedErr  error
+	}{
+		{
+			name:         "Success",
+			inputHandler: "succeeding",
+			wantDeleted:  true,
+		},
+		{
+			name:         "Error - Handler Failed",
+			inputHandler: "failing",
+			wantErr:      true,
+			expectedErr:  services.ErrReplayFailed,
+		},
+		{
+			name:         "Error - Unknown Handler",
+			inputHandler: "removed",
+			wantErr:      true,
+			expectedErr:  services.ErrReplayFailed,
+		},
+		{
+			name:         "Error - Dead Letter Not Found",
+			inputHandler: "succeeding",
+			inputID:      -1,
+			wantErr:      true,
+			expectedErr:  services.ErrDeadLetterNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replayed *message.Message
+
+			store, _ := servicetest.Setup(t)
+			service := NewBaseDeadLetterService(store, map[string]message.NoPublishHandlerFunc{
+				"succeeding": func(msg *message.Message) error {
+					replayed = msg
+					return nil
+				},
+				"failing": func(*message.Message) error {
+					return errors.New("entity not found")
+				},
+			})
+
+			deadLetter, err := service.AddDeadLetter(types.DeadLetter{
+				MessageID: "msg1",
+				Handler:   tt.inputHandler,
+				Topic:     "entities/*/state",
+				Payload:   []byte(`{"entity_id":"1","state":{"power":"on"}}`),
+				Metadata:  map[string]string{"content_type": "application/json"},
+				Reason:    "datastore unavailable",
+			})
+			if err != nil {
+				t.Fatalf("failed to add dead letter: %v", err)
+			}
+
+			id := deadLetter.ID
+			if tt.inputID != 0 {
+				id = tt.inputID
+			}
+
+			err = service.ReplayDeadLetter(id)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			_, err = service.GetDeadLetterByID(deadLetter.ID)
+			if deleted := errors.Is(err, services.ErrDeadLetterNotFound); deleted != tt.wantDeleted {
+				t.Errorf("Test failed. Expected deleted: %t, Got: %v", tt.wantDeleted, err)
+			}
+
+			if !tt.wantDeleted {
+				return
+			}
+
+			if replayed == nil || replayed.UUID != "msg1" || string(replayed.Payload) != string(deadLetter.Payload) || replayed.Metadata.Get("content_type") != "application/json" {
+				t.Errorf("Test failed. Unexpected replayed message: %+v", replayed)
+			}
+		})
+	}
+}
@@ -4,26 +4,43 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
+	"github.com/pmoura-dev/esr-service/internal/_data"
 	"github.com/pmoura-dev/esr-service/internal/broker"
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
 	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/filters"
+	"github.com/pmoura-dev/esr-service/internal/events"
 	"github.com/pmoura-dev/esr-service/internal/services"
 	"github.com/pmoura-dev/esr-service/internal/types"
 
-	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/google/uuid"
 )
 
+const (
+	reasonCommandTimedOut = "command timed out"
+
+	eventTypeCommandIssued = "esr.command.issued"
+)
+
 type BaseEntityService struct {
 	datastore datastore.DataStore
 	broker    broker.Broker
+	codec     codec.Codec
+	events    *events.Bus
+
+	defaultCommandTimeout time.Duration
 }
 
-func NewBaseEntityService(datastore datastore.DataStore, broker broker.Broker) *BaseEntityService {
+func NewBaseEntityService(datastore datastore.DataStore, broker broker.Broker, codec codec.Codec, events *events.Bus, defaultCommandTimeout time.Duration) *BaseEntityService {
 	return &BaseEntityService{
-		datastore: datastore,
-		broker:    broker,
+		datastore:             datastore,
+		broker:                broker,
+		codec:                 codec,
+		events:                events,
+		defaultCommandTimeout: defaultCommandTimeout,
 	}
 }
 
@@ -41,13 +58,20 @@ func (s *BaseEntityService) GetEntityByID(id string) (types.Entity, error) {
 	return entity, nil
 }
 
-func (s *BaseEntityService) ListEntities() ([]types.Entity, error) {
-	entityList, err := s.datastore.ListEntities()
+func (s *BaseEntityService) ListEntities(options datastore.ListOptions) (datastore.Page[types.Entity], error) {
+	page, err := s.datastore.ListEntities(options)
 	if err != nil {
-		return nil, services.ErrInternalError
+		switch {
+		case errors.Is(err, datastore.ErrInvalidCursor):
+			return datastore.Page[types.Entity]{}, services.ErrInvalidCursor
+		case errors.Is(err, datastore.ErrInvalidSortField):
+			return datastore.Page[types.Entity]{}, services.ErrInvalidSortField
+		default:
+			return datastore.Page[types.Entity]{}, services.ErrInternalError
+		}
 	}
 
-	return entityList, nil
+	return page, nil
 }
 
 func (s *BaseEntityService) AddEntity(entity types.Entity) error {
@@ -63,11 +87,19 @@ func (s *BaseEntityService) AddEntity(entity types.Entity) error {
 	return nil
 }
 
-func (s *BaseEntityService) DeleteEntity(id string) error {
-	if err := s.datastore.DeleteEntity(id); err != nil {
+func (s *BaseEntityService) DeleteEntity(id string, cascade bool) error {
+	policy := datastore.DeletionPolicyRestrict
+	if cascade {
+		policy = datastore.DeletionPolicyCascade
+	}
+
+	if err := s.datastore.DeleteEntity(id, policy); err != nil {
+		var referencedErr *datastore.ReferencedError
 		switch {
 		case errors.Is(err, datastore.ErrRecordNotFound):
 			return services.ErrEntityNotFound
+		case errors.As(err, &referencedErr):
+			return &services.EntityReferencedError{References: referencedErr.References}
 		default:
 			return services.ErrInternalError
 		}
@@ -76,7 +108,7 @@ func (s *BaseEntityService) DeleteEntity(id string) error {
 	return nil
 }
 
-func (s *BaseEntityService) ProcessCommand(entityID string, desiredState map[string]any) (string, error) {
+func (s *BaseEntityService) ProcessCommand(entityID string, desiredState map[string]any, timeout time.Duration) (string, error) {
 	// check if entity exists
 	_, err := s.datastore.GetEntityByID(entityID)
 	if err != nil {
@@ -88,33 +120,225 @@ func (s *BaseEntityService) ProcessCommand(entityID string, desiredState map[str
 		}
 	}
 
+	if timeout <= 0 {
+		timeout = s.defaultCommandTimeout
+	}
+
 	commandID := generateCommandID()
+	issuedAt := time.Now()
 
 	command := types.Command{
 		ID:           commandID,
 		EntityID:     entityID,
 		DesiredState: desiredState,
 		Status:       types.CommandStatusPending,
-		IssuedAt:     time.Now(),
+		IssuedAt:     issuedAt,
+		TimeoutAt:    _data.Ptr(issuedAt.Add(timeout)),
 	}
 
-	if err := s.datastore.AddCommand(command); err != nil {
+	replyTo := s.broker.Format(fmt.Sprintf("entities/%s/state", entityID))
+	payload, err := json.Marshal(types.NewCommandMessage(command, replyTo))
+	if err != nil {
 		return "", services.ErrInternalError
 	}
 
-	topic := s.broker.Format(fmt.Sprintf("entities/%s/update", entityID))
-	payload, err := json.Marshal(desiredState)
+	msg, err := s.codec.Encode(codec.Event{
+		ID:            uuid.NewString(),
+		Type:          eventTypeCommandIssued,
+		Subject:       entityID,
+		Time:          issuedAt,
+		ContentType:   codec.ContentTypeJSON,
+		CorrelationID: commandID,
+		Data:          payload,
+	})
 	if err != nil {
 		return "", services.ErrInternalError
 	}
 
-	if err := s.broker.GetPublisher().Publish(topic, message.NewMessage(commandID, payload)); err != nil {
-		return "", services.ErrInternalError
+	// the command is published by the outbox relay once it is stored, so it
+	// is never published without being stored, nor stored without being published
+	outboxMessage := types.OutboxMessage{
+		MessageID: msg.UUID,
+		EntityID:  entityID,
+		Topic:     s.broker.Format(fmt.Sprintf("entities/%s/update", entityID)),
+		Payload:   msg.Payload,
+		Metadata:  msg.Metadata,
+		CreatedAt: issuedAt,
+	}
+
+	if err := s.datastore.AddCommand(command, outboxMessage); err != nil {
+		switch {
+		// the entity was deleted since it was checked
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return "", services.ErrEntityNotFound
+		default:
+			return "", services.ErrInternalError
+		}
 	}
 
+	s.events.Publish(events.Event{
+		Type:      events.TypeCommandCreated,
+		EntityID:  entityID,
+		CommandID: commandID,
+		Data:      command,
+	})
+
 	return commandID, nil
 }
 
+func (s *BaseEntityService) ProcessStateReport(entityID string, state map[string]any, reportedAt time.Time) error {
+	// check if entity exists
+	_, err := s.datastore.GetEntityByID(entityID)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrEntityNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	report := types.State{
+		EntityID:   entityID,
+		State:      state,
+		ReportedAt: reportedAt,
+	}
+
+	if err := s.datastore.AddState(report); err != nil {
+		return services.ErrInternalError
+	}
+
+	s.events.Publish(events.Event{
+		Type:     events.TypeStateReported,
+		EntityID: entityID,
+		Data: types.StateReport{
+			EntityID:   entityID,
+			State:      state,
+			ReportedAt: &reportedAt,
+		},
+	})
+
+	return s.reconcileCommands(entityID, state)
+}
+
+// ProcessCommandAck resolves a pending command as acknowledged by the device.
+// Acks of commands that are already resolved, e.g. redelivered or arriving
+// after the command timed out, are ignored.
+func (s *BaseEntityService) ProcessCommandAck(entityID string, commandID string, status types.CommandAckStatus, reason string) error {
+	command, err := s.datastore.GetCommandByID(commandID)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrCommandNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	// a command can only be acknowledged by the entity it was sent to
+	if command.EntityID != entityID {
+		return services.ErrCommandNotFound
+	}
+
+	if command.Status != types.CommandStatusPending {
+		return nil
+	}
+
+	var result types.CommandStatus
+	switch status {
+	case types.CommandAckStatusCompleted:
+		result = types.CommandStatusSuccess
+		reason = ""
+	case types.CommandAckStatusRejected:
+		result = types.CommandStatusFailure
+	default:
+		return nil
+	}
+
+	return s.resolveCommand(command, result, reason)
+}
+
+// reconcileCommands resolves as successful every pending command of the entity
+// whose desired state is satisfied by the reported state.
+func (s *BaseEntityService) reconcileCommands(entityID string, reportedState map[string]any) error {
+	filter := filters.NewCommandFilter().
+		ByEntityID(entityID).
+		ByStatus(types.CommandStatusPending)
+
+	pendingCommands, err := s.datastore.ListCommands(filter, datastore.ListOptions{})
+	if err != nil {
+		return services.ErrInternalError
+	}
+
+	for _, command := range pendingCommands.Items {
+		if !matchesDesiredState(command.DesiredState, reportedState) {
+			continue
+		}
+
+		if err := s.resolveCommand(command, types.CommandStatusSuccess, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *BaseEntityService) FailTimedOutCommands() error {
+	filter := filters.NewCommandFilter().
+		ByStatus(types.CommandStatusPending).
+		ByTimeBeforeTimeout(time.Now())
+
+	timedOutCommands, err := s.datastore.ListCommands(filter, datastore.ListOptions{})
+	if err != nil {
+		return services.ErrInternalError
+	}
+
+	for _, command := range timedOutCommands.Items {
+		if err := s.resolveCommand(command, types.CommandStatusFailure, reasonCommandTimedOut); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveCommand resolves a pending command. A command resolved in the
+// meantime, e.g. by an ack racing the command reaper, is left as is.
+func (s *BaseEntityService) resolveCommand(command types.Command, status types.CommandStatus, reason string) error {
+	if err := s.datastore.ResolveCommand(command.ID, status, reason); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrAlreadyResolved):
+			return nil
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	command.Status = status
+	command.ResolvedAt = _data.Ptr(time.Now())
+	command.Reason = reason
+
+	s.events.Publish(events.Event{
+		Type:      events.TypeCommandResolved,
+		EntityID:  command.EntityID,
+		CommandID: command.ID,
+		Data:      command,
+	})
+
+	return nil
+}
+
 func generateCommandID() string {
 	return uuid.NewString()
 }
+
+func matchesDesiredState(desiredState map[string]any, reportedState map[string]any) bool {
+	for key, desiredValue := range desiredState {
+		reportedValue, ok := reportedState[key]
+		if !ok || !reflect.DeepEqual(desiredValue, reportedValue) {
+			return false
+		}
+	}
+
+	return true
+}
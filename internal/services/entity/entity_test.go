@@ -1 +1,434 @@
 package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/memory"
+	"github.com/pmoura-dev/esr-service/internal/events"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/services/servicetest"
+	"github.com/pmoura-dev/esr-service/internal/types"
+)
+
+func TestMatchesDesiredState(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputDesiredState  map[string]any
+		inputReportedState map[string]any
+		expected           bool
+	}{
+		{
+			name:               "Match - Same State",
+			inputDesiredState:  map[string]any{"power": "on"},
+			inputReportedState: map[string]any{"power": "on"},
+			expected:           true,
+		},
+		{
+			name:               "Match - Reported Superset",
+			inputDesiredState:  map[string]any{"power": "on"},
+			inputReportedState: map[string]any{"power": "on", "brightness": float64(80)},
+			expected:           true,
+		},
+		{
+			name:               "Match - Nested Values",
+			inputDesiredState:  map[string]any{"color": map[string]any{"r": float64(255)}},
+			inputReportedState: map[string]any{"color": map[string]any{"r": float64(255)}},
+			expected:           true,
+		},
+		{
+			name:               "No Match - Different Value",
+			inputDesiredState:  map[string]any{"power": "on"},
+			inputReportedState: map[string]any{"power": "off"},
+			expected:           false,
+		},
+		{
+			name:               "No Match - Missing Key",
+			inputDesiredState:  map[string]any{"power": "on", "brightness": float64(80)},
+			inputReportedState: map[string]any{"power": "on"},
+			expected:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchesDesiredState(tt.inputDesiredState, tt.inputReportedState)
+
+			if got != tt.expected {
+				t.Errorf("Test failed. Expected: %v, Got: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessCommand(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputEntityID string
+		inputState    map[string]any
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name:          "Success",
+			inputEntityID: "1",
+			inputState:    map[string]any{"power": "on"},
+		},
+		{
+			name:          "Error - Entity Not Found",
+			inputEntityID: "2",
+			inputState:    map[string]any{"power": "on"},
+			wantErr:       true,
+			expectedErr:   services.ErrEntityNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, bk := servicetest.Setup(t)
+			service := NewBaseEntityService(store, bk, codec.NewJSONCodec(), events.NewBus(), time.Minute)
+
+			if err := store.AddEntity(types.Entity{ID: "1", Name: "TestEntity1"}); err != nil {
+				t.Fatalf("failed to add entity: %v", err)
+			}
+
+			commandID, err := service.ProcessCommand(tt.inputEntityID, tt.inputState, 0)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			command, err := store.GetCommandByID(commandID)
+			if err != nil {
+				t.Errorf("Test failed. Command was not stored: %v", err)
+				return
+			}
+
+			if command.Status != types.CommandStatusPending || command.TimeoutAt == nil {
+				t.Errorf("Test failed. Unexpected command: %+v", command)
+			}
+
+			pending, err := store.ListPendingOutboxMessages(0)
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if len(pending) != 1 || pending[0].Topic != bk.Format("entities/1/update") {
+				t.Errorf("Test failed. Command was not added to the outbox: %+v", pending)
+				return
+			}
+
+			if pending[0].Metadata[codec.MetadataCorrelationID] != commandID {
+				t.Errorf("Test failed. Expected correlation ID: %s, Got: %+v", commandID, pending[0].Metadata)
+			}
+
+			var got types.CommandMessage
+			if err := json.Unmarshal(pending[0].Payload, &got); err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			expected := types.NewCommandMessage(command, bk.Format("entities/1/state"))
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessCommandAck(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputEntityID  string
+		inputCommandID string
+		inputStatus    types.CommandAckStatus
+		inputReason    string
+		resolved       bool
+		expectedStatus types.CommandStatus
+		expectedReason string
+		wantErr        bool
+		expectedErr    error
+	}{
+		{
+			name:           "Success - Completed",
+			inputEntityID:  "1",
+			inputStatus:    types.CommandAckStatusCompleted,
+			expectedStatus: types.CommandStatusSuccess,
+		},
+		{
+			name:           "Success - Rejected",
+			inputEntityID:  "1",
+			inputStatus:    types.CommandAckStatusRejected,
+			inputReason:    "E42: busy",
+			expectedStatus: types.CommandStatusFailure,
+			expectedReason: "E42: busy",
+		},
+		{
+			name:           "Success - Accepted",
+			inputEntityID:  "1",
+			inputStatus:    types.CommandAckStatusAccepted,
+			expectedStatus: types.CommandStatusPending,
+		},
+		{
+			name:           "Success - Already Resolved",
+			inputEntityID:  "1",
+			inputStatus:    types.CommandAckStatusCompleted,
+			resolved:       true,
+			expectedStatus: types.CommandStatusFailure,
+			expectedReason: reasonCommandTimedOut,
+		},
+		{
+			name:           "Error - Command Not Found",
+			inputEntityID:  "1",
+			inputCommandID: "missing",
+			inputStatus:    types.CommandAckStatusCompleted,
+			wantErr:        true,
+			expectedErr:    services.ErrCommandNotFound,
+		},
+		{
+			name:          "Error - Command Of Another Entity",
+			inputEntityID: "2",
+			inputStatus:   types.CommandAckStatusCompleted,
+			wantErr:       true,
+			expectedErr:   services.ErrCommandNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, bk := servicetest.Setup(t)
+			service := NewBaseEntityService(store, bk, codec.NewJSONCodec(), events.NewBus(), time.Minute)
+
+			if err := store.AddEntity(types.Entity{ID: "1", Name: "TestEntity1"}); err != nil {
+				t.Fatalf("failed to add entity: %v", err)
+			}
+
+			commandID, err := service.ProcessCommand("1", map[string]any{"power": "on"}, 0)
+			if err != nil {
+				t.Fatalf("failed to process command: %v", err)
+			}
+
+			if tt.resolved {
+				if err := store.ResolveCommand(commandID, types.CommandStatusFailure, reasonCommandTimedOut); err != nil {
+					t.Fatalf("failed to resolve command: %v", err)
+				}
+			}
+
+			if tt.inputCommandID != "" {
+				commandID = tt.inputCommandID
+			}
+
+			err = service.ProcessCommandAck(tt.inputEntityID, commandID, tt.inputStatus, tt.inputReason)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			command, err := store.GetCommandByID(commandID)
+			if err != nil {
+				t.Fatalf("failed to get command: %v", err)
+			}
+
+			if command.Status != tt.expectedStatus || command.Reason != tt.expectedReason {
+				t.Errorf("Test failed. Expected: %s (%s), Got: %s (%s)", tt.expectedStatus, tt.expectedReason, command.Status, command.Reason)
+			}
+		})
+	}
+}
+
+func TestDeleteEntity(t *testing.T) {
+	tests := []struct {
+		name string
+
+		inputEntityID string
+		inputCascade  bool
+		wantErr       bool
+		expectedErr   error
+	}{
+		{
+			name:          "Success - Cascade",
+			inputEntityID: "1",
+			inputCascade:  true,
+		},
+		{
+			name:          "Error - Entity Referenced",
+			inputEntityID: "1",
+			wantErr:       true,
+			expectedErr:   services.ErrEntityReferenced,
+		},
+		{
+			name:          "Error - Entity Not Found",
+			inputEntityID: "2",
+			wantErr:       true,
+			expectedErr:   services.ErrEntityNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, bk := servicetest.Setup(t)
+			service := NewBaseEntityService(store, bk, codec.NewJSONCodec(), events.NewBus(), time.Minute)
+
+			if err := store.AddEntity(types.Entity{ID: "1", Name: "TestEntity1"}); err != nil {
+				t.Fatalf("failed to add entity: %v", err)
+			}
+
+			commandID, err := service.ProcessCommand("1", map[string]any{"power": "on"}, 0)
+			if err != nil {
+				t.Fatalf("failed to process command: %v", err)
+			}
+
+			err = service.DeleteEntity(tt.inputEntityID, tt.inputCascade)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+
+				var referencedErr *services.EntityReferencedError
+				if errors.As(err, &referencedErr) && !reflect.DeepEqual([]string{commandID}, referencedErr.References.Commands) {
+					t.Errorf("Test failed. Expected: %+v, Got: %+v", []string{commandID}, referencedErr.References.Commands)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if _, err := store.GetCommandByID(commandID); !errors.Is(err, datastore.ErrRecordNotFound) {
+				t.Errorf("Test failed. Expected error: %v, Got: %v", datastore.ErrRecordNotFound, err)
+			}
+		})
+	}
+}
+
+func TestEvents(t *testing.T) {
+	store, bk := servicetest.Setup(t)
+	bus := events.NewBus()
+	service := NewBaseEntityService(store, bk, codec.NewJSONCodec(), bus, time.Minute)
+
+	if err := store.AddEntity(types.Entity{ID: "1", Name: "TestEntity1"}); err != nil {
+		t.Fatalf("failed to add entity: %v", err)
+	}
+
+	stream, unsubscribe := bus.Subscribe(events.ByEntityID("1"))
+
+	commandID, err := service.ProcessCommand("1", map[string]any{"power": "on"}, 0)
+	if err != nil {
+		t.Fatalf("failed to process command: %v", err)
+	}
+
+	if err := service.ProcessStateReport("1", map[string]any{"power": "on"}, time.Now()); err != nil {
+		t.Fatalf("failed to process state report: %v", err)
+	}
+
+	unsubscribe()
+
+	var got []events.Event
+	for event := range stream {
+		got = append(got, event)
+	}
+
+	expected := []events.Type{events.TypeCommandCreated, events.TypeStateReported, events.TypeCommandResolved}
+	if len(got) != len(expected) {
+		t.Fatalf("Test failed. Expected: %+v, Got: %+v", expected, got)
+	}
+
+	for i, event := range got {
+		if event.Type != expected[i] {
+			t.Errorf("Test failed. Expected: %s, Got: %s", expected[i], event.Type)
+		}
+	}
+
+	if got[2].CommandID != commandID {
+		t.Errorf("Test failed. Expected: %s, Got: %s", commandID, got[2].CommandID)
+	}
+
+	if command, ok := got[2].Data.(types.Command); !ok || command.Status != types.CommandStatusSuccess || command.ResolvedAt == nil {
+		t.Errorf("Test failed. Unexpected command: %+v", got[2].Data)
+	}
+}
+
+// racingDataStore resolves every command it lists as successful before
+// returning them, like a state report handled by another instance would.
+type racingDataStore struct {
+	*memory.DataStore
+}
+
+func (s racingDataStore) ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error) {
+	page, err := s.DataStore.ListCommands(filter, options)
+	if err != nil {
+		return page, err
+	}
+
+	for _, command := range page.Items {
+		if err := s.DataStore.ResolveCommand(command.ID, types.CommandStatusSuccess, ""); err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+func TestFailTimedOutCommandsResolvedConcurrently(t *testing.T) {
+	store, bk := servicetest.Setup(t)
+	bus := events.NewBus()
+	service := NewBaseEntityService(racingDataStore{store}, bk, codec.NewJSONCodec(), bus, time.Minute)
+
+	if err := store.AddEntity(types.Entity{ID: "1", Name: "TestEntity1"}); err != nil {
+		t.Fatalf("failed to add entity: %v", err)
+	}
+
+	commandID, err := service.ProcessCommand("1", map[string]any{"power": "on"}, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("failed to process command: %v", err)
+	}
+
+	stream, unsubscribe := bus.Subscribe(events.ByCommandID(commandID))
+
+	if err := service.FailTimedOutCommands(); err != nil {
+		t.Errorf("Test failed. Unexpected error: %v", err)
+	}
+
+	unsubscribe()
+
+	command, err := store.GetCommandByID(commandID)
+	if err != nil {
+		t.Fatalf("failed to get command: %v", err)
+	}
+
+	if command.Status != types.CommandStatusSuccess || command.Reason != "" {
+		t.Errorf("Test failed. Expected: %s, Got: %s (%s)", types.CommandStatusSuccess, command.Status, command.Reason)
+	}
+
+	for event := range stream {
+		t.Errorf("Test failed. Unexpected event: %+v", event)
+	}
+}
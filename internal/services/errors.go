@@ -2,10 +2,35 @@ package services
 
 import (
 	"errors"
+
+	"github.com/pmoura-dev/esr-service/internal/types"
 )
 
 var (
-	ErrEntityNotFound      = errors.New("entity not found")
-	ErrEntityAlreadyExists = errors.New("entity already exists")
-	ErrInternalError       = errors.New("internal error")
+	ErrEntityNotFound             = errors.New("entity not found")
+	ErrEntityAlreadyExists        = errors.New("entity already exists")
+	ErrEntityReferenced           = errors.New("entity is still referenced")
+	ErrCommandNotFound            = errors.New("command not found")
+	ErrReportSubscriptionNotFound = errors.New("report subscription not found")
+	ErrDeadLetterNotFound         = errors.New("dead letter not found")
+	ErrInvalidCursor              = errors.New("cursor is invalid")
+	ErrInvalidSortField           = errors.New("sort field is invalid")
+	ErrPublishFailed              = errors.New("message could not be published")
+	ErrReplayFailed               = errors.New("message could not be replayed")
+	ErrInternalError              = errors.New("internal error")
 )
+
+// EntityReferencedError is returned when deleting an entity without cascading
+// while commands or report subscriptions still reference it. It matches
+// ErrEntityReferenced.
+type EntityReferencedError struct {
+	References types.EntityReferences
+}
+
+func (e *EntityReferencedError) Error() string {
+	return ErrEntityReferenced.Error()
+}
+
+func (e *EntityReferencedError) Unwrap() error {
+	return ErrEntityReferenced
+}
@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// BaseOutboxService publishes the messages other services store in the
+// outbox. A message is marked sent only after it was published, so delivery
+// is at-least-once: a message may be published again if marking it fails, or
+// if its claim expires before it is marked.
+type BaseOutboxService struct {
+	datastore datastore.DataStore
+	broker    broker.Broker
+
+	batchSize int
+	retention time.Duration
+	// claimTimeout is how long other instances of the service leave the
+	// messages claimed by this one alone.
+	claimTimeout time.Duration
+}
+
+func NewBaseOutboxService(datastore datastore.DataStore, broker broker.Broker, batchSize int, retention time.Duration, claimTimeout time.Duration) *BaseOutboxService {
+	return &BaseOutboxService{
+		datastore:    datastore,
+		broker:       broker,
+		batchSize:    batchSize,
+		retention:    retention,
+		claimTimeout: claimTimeout,
+	}
+}
+
+// RelayPendingMessages publishes every pending message in the order they were
+// stored. Messages are claimed before being published, so each one is only
+// published by one instance of the service at a time. It stops at the first
+// message that can not be published and releases the rest of its batch, so
+// it is the first one retried on the next run.
+func (s *BaseOutboxService) RelayPendingMessages() error {
+	for {
+		pending, err := s.datastore.ClaimPendingOutboxMessages(s.batchSize, time.Now(), s.claimTimeout)
+		if err != nil {
+			return services.ErrInternalError
+		}
+
+		for i, outboxMessage := range pending {
+			if err := s.broker.GetPublisher().Publish(outboxMessage.Topic, newMessage(outboxMessage)); err != nil {
+				s.release(pending[i:])
+				return services.ErrPublishFailed
+			}
+
+			if err := s.datastore.MarkOutboxMessageSent(outboxMessage.ID, time.Now()); err != nil {
+				s.release(pending[i+1:])
+				return services.ErrInternalError
+			}
+		}
+
+		if s.batchSize <= 0 || len(pending) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+// release drops the claim of messages that were not published. It is best
+// effort: a claim that is not released expires after the claim timeout.
+func (s *BaseOutboxService) release(messages []types.OutboxMessage) {
+	ids := make([]int, 0, len(messages))
+	for _, outboxMessage := range messages {
+		ids = append(ids, outboxMessage.ID)
+	}
+
+	_ = s.datastore.ReleaseOutboxMessages(ids)
+}
+
+// PurgeSentMessages deletes the messages sent longer than the retention ago.
+func (s *BaseOutboxService) PurgeSentMessages() error {
+	if err := s.datastore.DeleteSentOutboxMessages(time.Now().Add(-s.retention)); err != nil {
+		return services.ErrInternalError
+	}
+
+	return nil
+}
+
+func newMessage(outboxMessage types.OutboxMessage) *message.Message {
+	msg := message.NewMessage(outboxMessage.MessageID, outboxMessage.Payload)
+	for key, value := range outboxMessage.Metadata {
+		msg.Metadata.Set(key, value)
+	}
+
+	return msg
+}
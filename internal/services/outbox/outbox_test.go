@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/services/servicetest"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestRelayPendingMessages(t *testing.T) {
+	tests := []struct {
+		name string
+
+		failPublish     bool
+		claimedByOther  bool
+		expectedPending int
+		wantErr         bool
+		expectedErr     error
+	}{
+		{
+			name: "Success",
+		},
+		{
+			name:            "Error - Publish Failed",
+			failPublish:     true,
+			expectedPending: 2,
+			wantErr:         true,
+			expectedErr:     services.ErrPublishFailed,
+		},
+		{
+			name:            "Claimed By Another Instance",
+			claimedByOther:  true,
+			expectedPending: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, bk := servicetest.Setup(t)
+
+			var b broker.Broker = bk
+			if tt.failPublish {
+				b = failingBroker{Broker: bk}
+			}
+
+			// a batch size of 1 checks that every batch is relayed
+			service := NewBaseOutboxService(store, b, 1, time.Hour, time.Minute)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			messages, err := bk.GetSubscriber().Subscribe(ctx, "entities/1/update")
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+
+			if err := store.AddEntity(types.Entity{ID: "1", Name: "lamp"}); err != nil {
+				t.Fatalf("failed to add entity: %v", err)
+			}
+
+			err = store.AddCommand(
+				types.Command{ID: "cmd1", EntityID: "1", Status: types.CommandStatusPending},
+				types.OutboxMessage{MessageID: "cmd1", Topic: "entities/1/update", Metadata: map[string]string{"key": "value"}},
+				types.OutboxMessage{MessageID: "cmd2", Topic: "entities/1/update"},
+			)
+			if err != nil {
+				t.Fatalf("failed to add command: %v", err)
+			}
+
+			if tt.claimedByOther {
+				if _, err := store.ClaimPendingOutboxMessages(0, time.Now(), time.Minute); err != nil {
+					t.Fatalf("failed to claim outbox: %v", err)
+				}
+			}
+
+			err = service.RelayPendingMessages()
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Test failed. Expected error: %v, Got: %v", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			pending, err := store.ListPendingOutboxMessages(0)
+			if err != nil {
+				t.Fatalf("failed to list outbox: %v", err)
+			}
+
+			if len(pending) != tt.expectedPending {
+				t.Errorf("Test failed. Expected: %d pending messages, Got: %+v", tt.expectedPending, pending)
+			}
+
+			// messages that failed to be published are released
+			if tt.failPublish {
+				for _, message := range pending {
+					if message.ClaimedUntil != nil {
+						t.Errorf("Test failed. Expected released messages, Got: %+v", pending)
+					}
+				}
+			}
+
+			if tt.expectedPending > 0 {
+				return
+			}
+
+			// the in-memory broker does not guarantee ordering between messages
+			received := make(map[string]message.Metadata)
+			for range 2 {
+				select {
+				case msg := <-messages:
+					msg.Ack()
+					received[msg.UUID] = msg.Metadata
+				case <-time.After(time.Second):
+					t.Fatalf("Test failed. Expected 2 published messages, Got: %+v", received)
+				}
+			}
+
+			if _, ok := received["cmd2"]; !ok || received["cmd1"].Get("key") != "value" {
+				t.Errorf("Test failed. Unexpected published messages: %+v", received)
+			}
+		})
+	}
+}
+
+type failingBroker struct {
+	broker.Broker
+}
+
+func (b failingBroker) GetPublisher() message.Publisher {
+	return failingPublisher{}
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(string, ...*message.Message) error {
+	return errors.New("broker unavailable")
+}
+
+func (failingPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,197 @@
+package report_subscription
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker"
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/datastore"
+	"github.com/pmoura-dev/esr-service/internal/services"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	eventTypeReportingConfigured = "esr.reporting.configured"
+)
+
+// reportingConfiguration is the control message that tells a device which
+// states and metrics it should be reporting.
+type reportingConfiguration struct {
+	EntityID      string                     `json:"entity_id"`
+	Subscriptions []types.ReportSubscription `json:"subscriptions"`
+}
+
+type BaseReportSubscriptionService struct {
+	datastore datastore.DataStore
+	broker    broker.Broker
+	codec     codec.Codec
+}
+
+func NewBaseReportSubscriptionService(datastore datastore.DataStore, broker broker.Broker, codec codec.Codec) *BaseReportSubscriptionService {
+	return &BaseReportSubscriptionService{
+		datastore: datastore,
+		broker:    broker,
+		codec:     codec,
+	}
+}
+
+func (s *BaseReportSubscriptionService) GetReportSubscriptionByID(entityID string, id int) (types.ReportSubscription, error) {
+	subscription, err := s.datastore.GetReportSubscriptionByID(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return types.ReportSubscription{}, services.ErrReportSubscriptionNotFound
+		default:
+			return types.ReportSubscription{}, services.ErrInternalError
+		}
+	}
+
+	// a subscription is only reachable through the entity it belongs to
+	if subscription.EntityID != entityID {
+		return types.ReportSubscription{}, services.ErrReportSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+func (s *BaseReportSubscriptionService) ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription], options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error) {
+	page, err := s.datastore.ListReportSubscriptions(filter, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrInvalidCursor):
+			return datastore.Page[types.ReportSubscription]{}, services.ErrInvalidCursor
+		case errors.Is(err, datastore.ErrInvalidSortField):
+			return datastore.Page[types.ReportSubscription]{}, services.ErrInvalidSortField
+		default:
+			return datastore.Page[types.ReportSubscription]{}, services.ErrInternalError
+		}
+	}
+
+	return page, nil
+}
+
+func (s *BaseReportSubscriptionService) AddReportSubscription(reportSubscription types.ReportSubscription) (types.ReportSubscription, error) {
+	// check if entity exists
+	_, err := s.datastore.GetEntityByID(reportSubscription.EntityID)
+	if err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return types.ReportSubscription{}, services.ErrEntityNotFound
+		default:
+			return types.ReportSubscription{}, services.ErrInternalError
+		}
+	}
+
+	reportSubscription.UpdatedAt = time.Now()
+
+	id, err := s.datastore.AddReportSubscription(reportSubscription, s.announceReportingConfiguration)
+	if err != nil {
+		switch {
+		// the entity was deleted since it was checked
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return types.ReportSubscription{}, services.ErrEntityNotFound
+		default:
+			return types.ReportSubscription{}, services.ErrInternalError
+		}
+	}
+
+	reportSubscription.ID = id
+
+	return reportSubscription, nil
+}
+
+func (s *BaseReportSubscriptionService) DeleteReportSubscription(entityID string, id int) error {
+	if _, err := s.GetReportSubscriptionByID(entityID, id); err != nil {
+		return err
+	}
+
+	if err := s.datastore.DeleteReportSubscription(id, s.announceReportingConfiguration); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrReportSubscriptionNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	return nil
+}
+
+func (s *BaseReportSubscriptionService) ActivateReportSubscription(entityID string, id int) error {
+	if _, err := s.GetReportSubscriptionByID(entityID, id); err != nil {
+		return err
+	}
+
+	if err := s.datastore.ActivateReportSubscription(id, s.announceReportingConfiguration); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrReportSubscriptionNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	return nil
+}
+
+func (s *BaseReportSubscriptionService) DeactivateReportSubscription(entityID string, id int) error {
+	if _, err := s.GetReportSubscriptionByID(entityID, id); err != nil {
+		return err
+	}
+
+	if err := s.datastore.DeactivateReportSubscription(id, s.announceReportingConfiguration); err != nil {
+		switch {
+		case errors.Is(err, datastore.ErrRecordNotFound):
+			return services.ErrReportSubscriptionNotFound
+		default:
+			return services.ErrInternalError
+		}
+	}
+
+	return nil
+}
+
+// announceReportingConfiguration returns the outbox message sending the full
+// set of active subscriptions of the entity, so the device can configure what
+// it reports. It is stored with the change, and published by the outbox relay,
+// which retries it until the broker takes it.
+func (s *BaseReportSubscriptionService) announceReportingConfiguration(entityID string, active []types.ReportSubscription) ([]types.OutboxMessage, error) {
+	configuration := reportingConfiguration{
+		EntityID:      entityID,
+		Subscriptions: active,
+	}
+
+	topic := s.broker.Format(fmt.Sprintf("entities/%s/reporting", entityID))
+	payload, err := json.Marshal(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	configuredAt := time.Now()
+
+	msg, err := s.codec.Encode(codec.Event{
+		ID:          uuid.NewString(),
+		Type:        eventTypeReportingConfigured,
+		Subject:     entityID,
+		Time:        configuredAt,
+		ContentType: codec.ContentTypeJSON,
+		Data:        payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []types.OutboxMessage{{
+		MessageID: msg.UUID,
+		EntityID:  entityID,
+		Topic:     topic,
+		Payload:   msg.Payload,
+		Metadata:  msg.Metadata,
+		CreatedAt: configuredAt,
+	}}, nil
+}
@@ -0,0 +1,96 @@
+package report_subscription
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/codec"
+	"github.com/pmoura-dev/esr-service/internal/services/outbox"
+	"github.com/pmoura-dev/esr-service/internal/services/servicetest"
+	"github.com/pmoura-dev/esr-service/internal/types"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestReportingConfiguration(t *testing.T) {
+	store, bk := servicetest.Setup(t)
+
+	cd := codec.NewJSONCodec()
+	service := NewBaseReportSubscriptionService(store, bk, cd)
+	relay := outbox.NewBaseOutboxService(store, bk, 0, time.Hour, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	messages, err := bk.GetSubscriber().Subscribe(ctx, "entities/1/reporting")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	if err := store.AddEntity(types.Entity{ID: "1", Name: "lamp"}); err != nil {
+		t.Fatalf("failed to add entity: %v", err)
+	}
+
+	// receive relays the stored configuration and returns the IDs of the
+	// active subscriptions it is published with
+	receive := func(t *testing.T) []int {
+		t.Helper()
+
+		if err := relay.RelayPendingMessages(); err != nil {
+			t.Fatalf("failed to relay outbox: %v", err)
+		}
+
+		var msg *message.Message
+		select {
+		case msg = <-messages:
+			msg.Ack()
+		case <-time.After(time.Second):
+			t.Fatalf("Test failed. Reporting configuration was not published")
+		}
+
+		event, err := cd.Decode(msg)
+		if err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+
+		var configuration reportingConfiguration
+		if err := json.Unmarshal(event.Data, &configuration); err != nil {
+			t.Fatalf("failed to decode configuration: %v", err)
+		}
+
+		if configuration.EntityID != "1" {
+			t.Errorf("Test failed. Expected: %s, Got: %s", "1", configuration.EntityID)
+		}
+
+		ids := []int{}
+		for _, subscription := range configuration.Subscriptions {
+			ids = append(ids, subscription.ID)
+		}
+
+		return ids
+	}
+
+	added, err := service.AddReportSubscription(types.ReportSubscription{
+		EntityID:   "1",
+		ReportType: types.ReportTypeState,
+		IsActive:   true,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if got := receive(t); !reflect.DeepEqual([]int{added.ID}, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", []int{added.ID}, got)
+	}
+
+	if err := service.DeactivateReportSubscription("1", added.ID); err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	if got := receive(t); !reflect.DeepEqual([]int{}, got) {
+		t.Errorf("Test failed. Expected: %+v, Got: %+v", []int{}, got)
+	}
+}
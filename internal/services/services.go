@@ -1,20 +1,49 @@
 package services
 
 import (
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/datastore"
 	"github.com/pmoura-dev/esr-service/internal/types"
 )
 
 type EntityService interface {
 	GetEntityByID(id string) (types.Entity, error)
-	ListEntities() ([]types.Entity, error)
+	ListEntities(options datastore.ListOptions) (datastore.Page[types.Entity], error)
 	AddEntity(entity types.Entity) error
-	DeleteEntity(id string) error
+	DeleteEntity(id string, cascade bool) error
 
-	ProcessCommand(entityID string, desiredState map[string]any) (string, error)
+	ProcessCommand(entityID string, desiredState map[string]any, timeout time.Duration) (string, error)
+	ProcessStateReport(entityID string, state map[string]any, reportedAt time.Time) error
+	ProcessCommandAck(entityID string, commandID string, status types.CommandAckStatus, reason string) error
+	FailTimedOutCommands() error
 }
 
 type CommandService interface {
-	GetCommandByID(id string)
+	GetCommandByID(id string) (types.Command, error)
+	ListCommands(filter datastore.Filter[types.Command], options datastore.ListOptions) (datastore.Page[types.Command], error)
+}
+
+type ReportSubscriptionService interface {
+	GetReportSubscriptionByID(entityID string, id int) (types.ReportSubscription, error)
+	ListReportSubscriptions(filter datastore.Filter[types.ReportSubscription], options datastore.ListOptions) (datastore.Page[types.ReportSubscription], error)
+	AddReportSubscription(reportSubscription types.ReportSubscription) (types.ReportSubscription, error)
+	DeleteReportSubscription(entityID string, id int) error
+	ActivateReportSubscription(entityID string, id int) error
+	DeactivateReportSubscription(entityID string, id int) error
 }
 
-type ReportSubscriptionService interface{}
+type OutboxService interface {
+	RelayPendingMessages() error
+	PurgeSentMessages() error
+}
+
+type DeadLetterService interface {
+	GetDeadLetterByID(id int) (types.DeadLetter, error)
+	ListDeadLetters(options datastore.ListOptions) (datastore.Page[types.DeadLetter], error)
+	AddDeadLetter(deadLetter types.DeadLetter) (types.DeadLetter, error)
+	// ReplayDeadLetter runs the failed handler again, and deletes the dead
+	// letter once it succeeds.
+	ReplayDeadLetter(id int) error
+	DeleteDeadLetter(id int) error
+}
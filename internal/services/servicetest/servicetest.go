@@ -0,0 +1,35 @@
+// Package servicetest provides the fixtures shared by the tests of the
+// services.
+package servicetest
+
+import (
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/broker/brokers/inmemory"
+	"github.com/pmoura-dev/esr-service/internal/config"
+	"github.com/pmoura-dev/esr-service/internal/datastore/databases/memory"
+)
+
+// Setup returns an initialized in-memory datastore and an in-memory broker,
+// both closed when the test ends.
+func Setup(t *testing.T) (*memory.DataStore, *inmemory.Broker) {
+	t.Helper()
+
+	store, err := memory.NewMemoryDataStore(config.DataStoreConfig{})
+	if err != nil {
+		t.Fatalf("failed to open datastore: %v", err)
+	}
+	t.Cleanup(store.Close)
+
+	if err := store.Init(); err != nil {
+		t.Fatalf("failed to init datastore: %v", err)
+	}
+
+	bk, err := inmemory.NewInMemoryBroker(config.BrokerConfig{})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	t.Cleanup(bk.Close)
+
+	return store, bk
+}
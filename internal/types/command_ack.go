@@ -0,0 +1,95 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/pmoura-dev/esr-service/internal/validation"
+)
+
+// CommandAck is sent by a device to tell whether it accepted, rejected or
+// completed a command.
+type CommandAck struct {
+	EntityID  string           `json:"entity_id"`
+	CommandID string           `json:"command_id"`
+	Status    CommandAckStatus `json:"status"`
+	// Error explains why a command was rejected.
+	Error *CommandAckError `json:"error,omitempty"`
+}
+
+type CommandAckError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Reason describes the error as stored on the command.
+func (e *CommandAckError) Reason() string {
+	if e == nil {
+		return ""
+	}
+
+	if e.Code == "" {
+		return e.Message
+	}
+
+	if e.Message == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Message
+}
+
+type CommandAckStatus string
+
+const (
+	// CommandAckStatusAccepted leaves the command pending until it completes.
+	CommandAckStatusAccepted  CommandAckStatus = "accepted"
+	CommandAckStatusRejected  CommandAckStatus = "rejected"
+	CommandAckStatusCompleted CommandAckStatus = "completed"
+)
+
+func ParseCommandAckStatus(status string) (CommandAckStatus, error) {
+	switch CommandAckStatus(status) {
+	case CommandAckStatusAccepted, CommandAckStatusRejected, CommandAckStatusCompleted:
+		return CommandAckStatus(status), nil
+	default:
+		return "", errors.New("invalid CommandAckStatus value")
+	}
+}
+
+func (s *CommandAckStatus) UnmarshalJSON(data []byte) error {
+	var status string
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+
+	parsed, err := ParseCommandAckStatus(status)
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+func (a CommandAck) Validate() validation.ErrorList {
+	errorList := validation.ErrorList{}
+
+	if a.EntityID == "" {
+		errorList = append(errorList, validation.RequiredError("entity_id"))
+	}
+
+	if a.CommandID == "" {
+		errorList = append(errorList, validation.RequiredError("command_id"))
+	}
+
+	if a.Status == "" {
+		errorList = append(errorList, validation.RequiredError("status"))
+	}
+
+	if a.Error != nil && a.Status != CommandAckStatusRejected {
+		errorList = append(errorList, validation.NotAllowedWhenError("error", "'status' is not 'rejected'"))
+	}
+
+	return errorList
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/validation"
+)
+
+func TestCommandAckValidate(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input    CommandAck
+		expected validation.ErrorList
+	}{
+		{
+			name:     "Valid - Completed",
+			input:    CommandAck{EntityID: "1", CommandID: "cmd1", Status: CommandAckStatusCompleted},
+			expected: validation.ErrorList{},
+		},
+		{
+			name:     "Valid - Rejected With Error",
+			input:    CommandAck{EntityID: "1", CommandID: "cmd1", Status: CommandAckStatusRejected, Error: &CommandAckError{Message: "busy"}},
+			expected: validation.ErrorList{},
+		},
+		{
+			name:  "Invalid - Missing Fields",
+			input: CommandAck{},
+			expected: validation.ErrorList{
+				validation.RequiredError("entity_id"),
+				validation.RequiredError("command_id"),
+				validation.RequiredError("status"),
+			},
+		},
+		{
+			name:  "Invalid - Accepted With Error",
+			input: CommandAck{EntityID: "1", CommandID: "cmd1", Status: CommandAckStatusAccepted, Error: &CommandAckError{Message: "busy"}},
+			expected: validation.ErrorList{
+				validation.NotAllowedWhenError("error", "'status' is not 'rejected'"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.Validate()
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommandAckUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input    string
+		expected CommandAck
+		wantErr  bool
+	}{
+		{
+			name:  "Valid",
+			input: `{"entity_id":"1","command_id":"cmd1","status":"rejected","error":{"code":"E42","message":"busy"}}`,
+			expected: CommandAck{
+				EntityID:  "1",
+				CommandID: "cmd1",
+				Status:    CommandAckStatusRejected,
+				Error:     &CommandAckError{Code: "E42", Message: "busy"},
+			},
+		},
+		{
+			name:    "Invalid Status",
+			input:   `{"entity_id":"1","command_id":"cmd1","status":"done"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CommandAck
+			err := json.Unmarshal([]byte(tt.input), &got)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Test failed. Expected an error, Got: %+v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+
+			if reason := got.Error.Reason(); reason != "E42: busy" {
+				t.Errorf("Test failed. Expected reason: %s, Got: %s", "E42: busy", reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"time"
+)
+
+// CommandMessageVersion is the version of the CommandMessage contract. It is
+// only bumped on breaking changes, fields may be added within a version.
+const CommandMessageVersion = 1
+
+// CommandMessage is the envelope commands are published to devices in.
+type CommandMessage struct {
+	Version      int            `json:"version"`
+	CommandID    string         `json:"command_id"`
+	EntityID     string         `json:"entity_id"`
+	DesiredState map[string]any `json:"desired_state"`
+	IssuedAt     time.Time      `json:"issued_at"`
+	ExpiresAt    *time.Time     `json:"expires_at"`
+	// ReplyTo is the topic the device reports the resulting state on.
+	ReplyTo string `json:"reply_to"`
+}
+
+func NewCommandMessage(command Command, replyTo string) CommandMessage {
+	return CommandMessage{
+		Version:      CommandMessageVersion,
+		CommandID:    command.ID,
+		EntityID:     command.EntityID,
+		DesiredState: command.DesiredState,
+		IssuedAt:     command.IssuedAt,
+		ExpiresAt:    command.TimeoutAt,
+		ReplyTo:      replyTo,
+	}
+}
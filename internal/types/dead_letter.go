@@ -0,0 +1,21 @@
+package types
+
+import (
+	"time"
+)
+
+// DeadLetter is an inbound message that could not be handled, even after
+// retries. It is kept until an operator replays or deletes it.
+type DeadLetter struct {
+	// ID is assigned by the datastore and orders dead letters by creation.
+	ID        int    `json:"id"`
+	MessageID string `json:"message_id"`
+	// Handler is the name of the handler that failed, which a replay runs again.
+	Handler string `json:"handler"`
+	// Topic is the topic the handler subscribes to, which may be a pattern.
+	Topic     string            `json:"topic"`
+	Payload   []byte            `json:"payload"`
+	Metadata  map[string]string `json:"metadata"`
+	Reason    string            `json:"reason"`
+	CreatedAt time.Time         `json:"created_at"`
+}
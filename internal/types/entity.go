@@ -22,3 +22,13 @@ func (e Entity) Validate() validation.ErrorList {
 
 	return errorList
 }
+
+// EntityReferences lists the records that still point at an entity.
+type EntityReferences struct {
+	Commands            []string `json:"commands"`
+	ReportSubscriptions []int    `json:"report_subscriptions"`
+}
+
+func (r EntityReferences) IsEmpty() bool {
+	return len(r.Commands) == 0 && len(r.ReportSubscriptions) == 0
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"time"
+)
+
+// OutboxMessage is a broker message stored in the same transaction as the
+// records it announces. It is published later by the outbox relay, so a
+// message is never lost nor sent for records that were not stored.
+type OutboxMessage struct {
+	// ID is assigned by the datastore and orders messages by creation.
+	ID        int    `json:"id"`
+	MessageID string `json:"message_id"`
+	// EntityID is the entity the message is about, its pending messages are
+	// deleted with it.
+	EntityID  string            `json:"entity_id"`
+	Topic     string            `json:"topic"`
+	Payload   []byte            `json:"payload"`
+	Metadata  map[string]string `json:"metadata"`
+	CreatedAt time.Time         `json:"created_at"`
+	SentAt    *time.Time        `json:"sent_at"`
+	// ClaimedUntil is when the claim of the relay that is publishing the
+	// message expires, and another relay may publish it.
+	ClaimedUntil *time.Time `json:"claimed_until"`
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"github.com/pmoura-dev/esr-service/internal/validation"
+)
+
+func (rs ReportSubscription) Validate() validation.ErrorList {
+	errorList := validation.ErrorList{}
+
+	if rs.EntityID == "" {
+		errorList = append(errorList, validation.RequiredError("entity_id"))
+	}
+
+	switch rs.ReportType {
+	case "":
+		errorList = append(errorList, validation.RequiredError("report_type"))
+	case ReportTypeMetric:
+		if rs.Metric == nil || *rs.Metric == "" {
+			errorList = append(errorList, validation.RequiredWhenError("metric", "'report_type' is 'metric'"))
+		}
+	case ReportTypeState:
+		if rs.Metric != nil {
+			errorList = append(errorList, validation.NotAllowedWhenError("metric", "'report_type' is 'state'"))
+		}
+	}
+
+	return errorList
+}
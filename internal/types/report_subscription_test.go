@@ -0,0 +1,70 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pmoura-dev/esr-service/internal/validation"
+)
+
+func TestReportSubscriptionValidate(t *testing.T) {
+	metric := "power"
+	emptyMetric := ""
+
+	tests := []struct {
+		name string
+
+		input    ReportSubscription
+		expected validation.ErrorList
+	}{
+		{
+			name:     "Valid - State",
+			input:    ReportSubscription{EntityID: "1", ReportType: ReportTypeState},
+			expected: validation.ErrorList{},
+		},
+		{
+			name:     "Valid - Metric",
+			input:    ReportSubscription{EntityID: "1", ReportType: ReportTypeMetric, Metric: &metric},
+			expected: validation.ErrorList{},
+		},
+		{
+			name:  "Invalid - Missing Fields",
+			input: ReportSubscription{},
+			expected: validation.ErrorList{
+				validation.RequiredError("entity_id"),
+				validation.RequiredError("report_type"),
+			},
+		},
+		{
+			name:  "Invalid - Metric Without Name",
+			input: ReportSubscription{EntityID: "1", ReportType: ReportTypeMetric},
+			expected: validation.ErrorList{
+				validation.RequiredWhenError("metric", "'report_type' is 'metric'"),
+			},
+		},
+		{
+			name:  "Invalid - Metric With Empty Name",
+			input: ReportSubscription{EntityID: "1", ReportType: ReportTypeMetric, Metric: &emptyMetric},
+			expected: validation.ErrorList{
+				validation.RequiredWhenError("metric", "'report_type' is 'metric'"),
+			},
+		},
+		{
+			name:  "Invalid - State With Metric",
+			input: ReportSubscription{EntityID: "1", ReportType: ReportTypeState, Metric: &metric},
+			expected: validation.ErrorList{
+				validation.NotAllowedWhenError("metric", "'report_type' is 'state'"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.Validate()
+
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Test failed. Expected: %+v, Got: %+v", tt.expected, got)
+			}
+		})
+	}
+}
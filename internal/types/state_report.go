@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/validation"
+)
+
+type StateReport struct {
+	EntityID   string         `json:"entity_id"`
+	State      map[string]any `json:"state"`
+	ReportedAt *time.Time     `json:"reported_at,omitempty"`
+}
+
+func (r StateReport) Validate() validation.ErrorList {
+	errorList := validation.ErrorList{}
+
+	if r.EntityID == "" {
+		errorList = append(errorList, validation.RequiredError("entity_id"))
+	}
+
+	if len(r.State) == 0 {
+		errorList = append(errorList, validation.RequiredError("state"))
+	}
+
+	return errorList
+}
@@ -12,7 +12,9 @@ type Command struct {
 	DesiredState map[string]any `json:"desired_state"`
 	Status       CommandStatus  `json:"status"`
 	IssuedAt     time.Time      `json:"issued_at"`
+	TimeoutAt    *time.Time     `json:"timeout_at"`
 	ResolvedAt   *time.Time     `json:"resolved_at"`
+	Reason       string         `json:"reason,omitempty"`
 }
 
 type CommandStatus string
@@ -23,26 +25,35 @@ const (
 	CommandStatusFailure CommandStatus = "failure"
 )
 
+func ParseCommandStatus(status string) (CommandStatus, error) {
+	switch CommandStatus(status) {
+	case CommandStatusPending, CommandStatusSuccess, CommandStatusFailure:
+		return CommandStatus(status), nil
+	default:
+		return "", errors.New("invalid CommandStatus value")
+	}
+}
+
 func (cs *CommandStatus) UnmarshalJSON(data []byte) error {
 	var status string
 	if err := json.Unmarshal(data, &status); err != nil {
 		return err
 	}
 
-	switch CommandStatus(status) {
-	case CommandStatusPending, CommandStatusSuccess, CommandStatusFailure:
-		*cs = CommandStatus(status)
-		return nil
-	default:
-		return errors.New("invalid CommandStatus value")
+	parsed, err := ParseCommandStatus(status)
+	if err != nil {
+		return err
 	}
+
+	*cs = parsed
+	return nil
 }
 
 type State struct {
-	ID         int
-	EntityID   string
-	State      map[string]any
-	ReportedAt time.Time
+	ID         int            `json:"id"`
+	EntityID   string         `json:"entity_id"`
+	State      map[string]any `json:"state"`
+	ReportedAt time.Time      `json:"reported_at"`
 }
 
 type ReportSubscription struct {
@@ -61,17 +72,26 @@ const (
 	ReportTypeMetric ReportType = "metric"
 )
 
+func ParseReportType(reportType string) (ReportType, error) {
+	switch ReportType(reportType) {
+	case ReportTypeState, ReportTypeMetric:
+		return ReportType(reportType), nil
+	default:
+		return "", errors.New("invalid ReportType value")
+	}
+}
+
 func (rt *ReportType) UnmarshalJSON(data []byte) error {
 	var reportType string
 	if err := json.Unmarshal(data, &reportType); err != nil {
 		return err
 	}
 
-	switch ReportType(reportType) {
-	case ReportTypeState, ReportTypeMetric:
-		*rt = ReportType(reportType)
-		return nil
-	default:
-		return errors.New("invalid ReportType value")
+	parsed, err := ParseReportType(reportType)
+	if err != nil {
+		return err
 	}
+
+	*rt = parsed
+	return nil
 }
@@ -17,3 +17,17 @@ func RequiredError(field string) ErrorDetail {
 		Message: fmt.Sprintf("'%s' is required", field),
 	}
 }
+
+func RequiredWhenError(field string, condition string) ErrorDetail {
+	return ErrorDetail{
+		Field:   field,
+		Message: fmt.Sprintf("'%s' is required when %s", field, condition),
+	}
+}
+
+func NotAllowedWhenError(field string, condition string) ErrorDetail {
+	return ErrorDetail{
+		Field:   field,
+		Message: fmt.Sprintf("'%s' is not allowed when %s", field, condition),
+	}
+}
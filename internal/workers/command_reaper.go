@@ -0,0 +1,38 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/services"
+)
+
+// CommandReaper periodically fails pending commands whose timeout has elapsed.
+type CommandReaper struct {
+	entityService services.EntityService
+	interval      time.Duration
+}
+
+func NewCommandReaper(entityService services.EntityService, interval time.Duration) *CommandReaper {
+	return &CommandReaper{
+		entityService: entityService,
+		interval:      interval,
+	}
+}
+
+func (r *CommandReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.entityService.FailTimedOutCommands(); err != nil {
+				slog.Error("failed to reap timed out commands", "error", err)
+			}
+		}
+	}
+}
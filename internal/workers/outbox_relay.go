@@ -0,0 +1,50 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pmoura-dev/esr-service/internal/services"
+)
+
+const (
+	outboxPurgeInterval = time.Hour
+)
+
+// OutboxRelay periodically publishes the pending outbox messages and purges
+// the ones sent long ago.
+type OutboxRelay struct {
+	outboxService services.OutboxService
+	interval      time.Duration
+}
+
+func NewOutboxRelay(outboxService services.OutboxService, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		outboxService: outboxService,
+		interval:      interval,
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	relayTicker := time.NewTicker(r.interval)
+	defer relayTicker.Stop()
+
+	purgeTicker := time.NewTicker(outboxPurgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-relayTicker.C:
+			if err := r.outboxService.RelayPendingMessages(); err != nil {
+				slog.Error("failed to relay outbox messages", "error", err)
+			}
+		case <-purgeTicker.C:
+			if err := r.outboxService.PurgeSentMessages(); err != nil {
+				slog.Error("failed to purge sent outbox messages", "error", err)
+			}
+		}
+	}
+}